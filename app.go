@@ -1,25 +1,103 @@
 package cligobrr
 
 import "fmt"
+import "io"
+import "os"
 import "strings"
+import "sync"
 
 type AppFields struct {
 	Name        string
 	Description string
 	Version     string
+
+	// Where help, version, warnings and command output go, and where
+	// prompts read from. These default to stdout, stderr and stdin.
+	Out io.Writer
+	Err io.Writer
+	In  io.Reader
+
+	// Strict runs Check the first time any input is resolved, so
+	// mistakes in the command tree are reported rather than quietly
+	// ignored. Whatever it finds is reported by every Resolve after
+	// that too, without checking again, so the tree should be done
+	// by then.
+	Strict bool
+
+	// SuggestDistance is the most edits a mistyped command, arg or
+	// value can be from a known one and still be offered as "Did you
+	// mean" it. Short ones get fewer, and the shortest get none. Zero
+	// means the default of 2, and a negative distance turns
+	// suggestions off.
+	SuggestDistance int
+
+	// HelpTemplate is a text/template that replaces the default help
+	// layout, or just the parts of it that it redefines. See HelpModel
+	// for what it has to work with.
+	HelpTemplate string
+
+	// Groups and SortCmds are CmdFields.Groups and CmdFields.SortCmds
+	// for the app's own commands.
+	Groups   []string
+	SortCmds CmdSort
+
+	// Output adds the output and columns args, which decide how the
+	// records returned by ExecWithOutput (or given to Context.Output)
+	// are written: as a table, JSON, YAML, CSV, TSV or Markdown.
+	Output bool
+
+	// Help and messages are colored when written to a terminal, unless
+	// NO_COLOR is set. Color adds the color arg, which can also say
+	// always or never. Styles changes how each Style is drawn, with
+	// SGR parameters like "1;34" for bold blue, or "" for none.
+	Color  bool
+	Styles map[Style]string
+
+	// Completion adds the completion command, which writes a script
+	// for bash, zsh, fish or PowerShell that completes commands, args
+	// and choices, along with the hidden command those scripts call
+	// to do the completing. See WriteCompletion and ArgFields.Complete.
+	Completion bool
+
+	// ManPages adds a hidden command, __man, that writes man pages
+	// for the app and its commands to the directory given by its dir
+	// arg. See WriteManPages.
+	ManPages bool
 }
 
 type App struct {
 	AppFields
-	Cmds Cmds
-	Args Args
+	Cmds     Cmds
+	Args     Args
+	warnings []Warning
+
+	// Strict apps are checked once, however many
+	// times and from however many goroutines they
+	// resolve input.
+	strict    sync.Once
+	strictErr error
 }
 
 func AppNew(fields AppFields) *App {
+	if fields.Out == nil {
+		fields.Out = os.Stdout
+	}
+
+	if fields.Err == nil {
+		fields.Err = os.Stderr
+	}
+
+	if fields.In == nil {
+		fields.In = os.Stdin
+	}
+
 	app := App{
 		AppFields: fields,
 	}
 
+	app.Cmds.app = &app
+	app.Args.app = &app
+
 	// No alias on help or version to avoid collisions
 	// with user-defined commands.
 	helpFields := CmdFields{
@@ -28,6 +106,7 @@ func AppNew(fields AppFields) *App {
 	}
 
 	helpCmd, _ := CmdNew(helpFields)
+	helpCmd.builtin = true
 	app.Cmds.Add(helpCmd)
 
 	versionFields := CmdFields{
@@ -36,12 +115,164 @@ func AppNew(fields AppFields) *App {
 	}
 
 	versionCmd, _ := CmdNew(versionFields)
+	versionCmd.builtin = true
 	app.Cmds.Add(versionCmd)
 
+	if fields.Output {
+		app.Args.Add(outputFormatArgNew(&app))
+
+		columnsFields := ArgFields{
+			Name:        outputColumnsArg,
+			Description: outputColumnsDesc,
+			Multiple:    true,
+			Global:      true,
+		}
+
+		columns, _ := StringArgNew(columnsFields)
+		app.Args.Add(columns)
+	}
+
+	if fields.Color {
+		colorFields := ArgFields{
+			Name:        colorArg,
+			Description: colorArgDesc,
+			Choices:     []string{ColorAuto, ColorAlways, ColorNever},
+			Default:     ColorAuto,
+		}
+
+		color, _ := StringArgNew(colorFields)
+		app.Args.Add(color)
+	}
+
+	if fields.Completion {
+		app.Cmds.Add(completionCmdNew(&app))
+		app.Cmds.Add(completeCmdNew())
+	}
+
+	if fields.ManPages {
+		app.Cmds.Add(manPagesCmdNew(&app))
+	}
+
 	return &app
 }
 
+// Parse resolves the input and renders any help or version output
+// straight away, returning the command the input resolved to. Use
+// Resolve and Render when printing needs to be kept separate.
+//
+// So that the accessors on App.Args and Cmd.Args keep working, Parse
+// also stores the parsed values on the definitions. That makes it
+// unsuitable for concurrent use; Resolve has no such problem.
 func (self *App) Parse(input []string) (*Cmd, error) {
+	self.warnings = nil
+
+	result, err := self.Resolve(input)
+	if err != nil {
+		return nil, err
+	}
+
+	self.Args.publish(result.AppArgs)
+	if result.Cmd != nil {
+		result.Cmd.Args.publish(result.Args)
+	}
+
+	self.warnings = result.Warnings
+	emitWarnings(self.Err, result.palette(self.Err), self.warnings)
+
+	err = result.Render()
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Cmd, nil
+}
+
+// Run resolves the input and then does whatever it asks for: help
+// and version are rendered, and anything else is executed.
+func (self *App) Run(input []string) error {
+	result, err := self.Resolve(input)
+	if err != nil {
+		return err
+	}
+
+	emitWarnings(self.Err, result.palette(self.Err), result.Warnings)
+
+	if result.Action != ActionRun {
+		return result.Render()
+	}
+
+	return result.Exec()
+}
+
+// Resolve works out what the input is asking for without
+// printing anything along the way.
+func (self *App) Resolve(input []string) (*Result, error) {
+	if self.Strict {
+		self.strict.Do(func() {
+			self.strictErr = self.Check()
+		})
+
+		if self.strictErr != nil {
+			return nil, self.strictErr
+		}
+	}
+
+	result := Result{
+		app: self,
+	}
+
+	// The words to complete are rarely a valid command line yet, so
+	// they are kept as they are rather than resolved.
+	complete := self.Cmds.get(completeName)
+	if len(input) > 1 && input[1] == completeName && complete != nil && complete.builtin {
+		result.Action = ActionComplete
+		result.Cmd = complete
+		result.Complete = input[2:]
+		_ = result.unparsed()
+		return &result, nil
+	}
+
+	if len(input) > 0 && result.helpFlag(nil, input[1:]) {
+		// Help is wanted, so a bad value in the
+		// environment isn't worth failing over.
+		_ = result.unparsed()
+		return &result, nil
+	}
+
+	cmd, err := self.resolve(input, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Cmd = cmd
+
+	err = result.parseAppInput()
+	if err != nil {
+		return nil, err
+	}
+
+	err = result.unparsed()
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// PrintError writes err to Err, colored like any other error when
+// color is on, for apps that want failures to match their help.
+func (self *App) PrintError(err error) {
+	colors := paletteFor(self.Err, colorMode(&self.Args), self.Styles)
+	fmt.Fprintln(self.Err, colors.paint(StyleError, err.Error()))
+}
+
+// Warnings returns anything deprecated that was used
+// during the most recent Parse.
+func (self *App) Warnings() []Warning {
+	return self.warnings
+}
+
+func (self *App) resolve(input []string, result *Result) (*Cmd, error) {
 	// Remove the app name.
 	input = input[1:]
 
@@ -56,7 +287,7 @@ func (self *App) Parse(input []string) (*Cmd, error) {
 			return defCmd, nil
 		}
 
-		appHelp(self, []string{})
+		result.help(nil, nil)
 		return self.Cmds.get("help"), nil
 	}
 
@@ -76,12 +307,9 @@ func (self *App) Parse(input []string) (*Cmd, error) {
 		args = append(args, token)
 	}
 
-	if len(args) > 0 {
-		err := self.Args.Parse(args)
-		if err != nil {
-			return nil, err
-		}
-	}
+	// They're parsed once the command is known, since global
+	// args can turn up after it too.
+	result.appInput = args
 
 	if len(input) == 0 {
 		// There is no input remaining. If there is a
@@ -90,7 +318,7 @@ func (self *App) Parse(input []string) (*Cmd, error) {
 			return defCmd, nil
 		}
 
-		appHelp(self, []string{})
+		result.help(nil, nil)
 		return self.Cmds.get("help"), nil
 	}
 
@@ -102,7 +330,7 @@ func (self *App) Parse(input []string) (*Cmd, error) {
 		input = input[1:]
 
 		if cmd.Name == "help" {
-			err := appHelp(self, input)
+			err := result.help(nil, input)
 			if err != nil {
 				return nil, err
 			} else {
@@ -111,12 +339,14 @@ func (self *App) Parse(input []string) (*Cmd, error) {
 		}
 
 		if cmd.Name == "version" {
-			self.version()
+			result.Action = ActionVersion
 			return cmd, nil
 		}
 
 		// Some other commmand.
-		cmd, err := cmd.Parse(input)
+		result.deprecated(deprecatedCmd, cmd.Name, cmd.Deprecated)
+
+		cmd, err := cmd.resolve(input, result)
 		if err != nil {
 			return nil, err
 		} else {
@@ -126,7 +356,7 @@ func (self *App) Parse(input []string) (*Cmd, error) {
 		// No command matching token. If there is a default command,
 		// let's assume the input is args for that.
 		if defCmd != nil {
-			cmd, err := defCmd.Parse(input)
+			cmd, err := defCmd.resolve(input, result)
 			if err != nil {
 				return nil, err
 			} else {
@@ -135,17 +365,17 @@ func (self *App) Parse(input []string) (*Cmd, error) {
 		} else {
 			// No default command, so let's say we don't know what
 			// to do with the input.
-			return nil, errUnexpectedCmd(token)
+			return nil, errUnexpectedCmd(token, self.suggest(token, self.Cmds.identifiers()))
 		}
 	}
 }
 
-func (self *App) version() {
+func (self *App) version(out io.Writer) {
 	ver := strings.TrimSpace(self.Version)
 
 	if len(self.Version) == 0 {
 		ver = "undefined"
 	}
 
-	fmt.Println(self.Name, "version", ver)
+	fmt.Fprintln(out, self.Name, "version", ver)
 }
@@ -1,6 +1,8 @@
 package cligobrr
 
 import "fmt"
+import "bytes"
+import "os"
 import "testing"
 import "github.com/stretchr/testify/assert"
 
@@ -148,6 +150,63 @@ func TestAppParseCommandDoesNotExist(t *testing.T) {
 	assert.NotNil(err)
 }
 
+func TestAppParseCommandSuggestion(t *testing.T) {
+	assert := assert.New(t)
+
+	appFields := AppFields{
+		Name: testAppName,
+	}
+
+	app := AppNew(appFields)
+
+	cmdFields := CmdFields{
+		Name: "deploy",
+		Exec: testCmdExec,
+	}
+
+	cmd, err := CmdNew(cmdFields)
+	assert.Nil(err)
+
+	app.Cmds.Add(cmd)
+
+	cmdToExec, err := app.Parse([]string{testAppName, "delpoy"})
+	assert.Nil(cmdToExec)
+	assert.EqualError(err, "Unexpected command: delpoy. Did you mean: deploy?")
+}
+
+func TestAppParseDoesNotSuggestHidden(t *testing.T) {
+	assert := assert.New(t)
+
+	app := AppNew(AppFields{Name: testAppName, Completion: true})
+
+	cmd, err := CmdNew(CmdFields{Name: "rotate-keys", Hidden: true, Exec: testCmdExec})
+	assert.Nil(err)
+
+	arg, err := BoolArgNew(ArgFields{Name: "verbose", Hidden: true})
+	assert.Nil(err)
+
+	cmd.Args.Add(arg)
+	app.Cmds.Add(cmd)
+
+	_, err = app.Parse([]string{testAppName, "rotate-kyes"})
+	assert.EqualError(err, "Unexpected command: rotate-kyes.")
+
+	_, err = app.Parse([]string{testAppName, "__compleet"})
+	assert.EqualError(err, "Unexpected command: __compleet.")
+
+	// A name of your own is fair game, however it's spelt.
+	internal, err := CmdNew(CmdFields{Name: "__sync", Exec: testCmdExec})
+	assert.Nil(err)
+	app.Cmds.Add(internal)
+
+	_, err = app.Parse([]string{testAppName, "__synk"})
+	assert.EqualError(err, "Unexpected command: __synk. Did you mean: __sync?")
+
+	// The same goes for hidden args.
+	_, err = app.Parse([]string{testAppName, "rotate-keys", "verbsoe=true"})
+	assert.EqualError(err, "Unexpected argument: verbsoe.")
+}
+
 func TestAppParseNoArgsWithDefaultCommand(t *testing.T) {
 	assert := assert.New(t)
 
@@ -173,3 +232,141 @@ func TestAppParseNoArgsWithDefaultCommand(t *testing.T) {
 	assert.Nil(err)
 	assert.Equal(cmd.Name, cmdToExec.Name)
 }
+
+func TestAppWriters(t *testing.T) {
+	assert := assert.New(t)
+
+	var out bytes.Buffer
+	var errOut bytes.Buffer
+
+	appFields := AppFields{
+		Name:    testAppName,
+		Version: testAppVersion,
+		Out:     &out,
+		Err:     &errOut,
+	}
+
+	app := AppNew(appFields)
+
+	_, err := app.Parse([]string{testAppName, "version"})
+	assert.Nil(err)
+	assert.Equal("myApp version 0.1.0\n", out.String())
+
+	out.Reset()
+	_, err = app.Parse([]string{testAppName, "help"})
+	assert.Nil(err)
+	assert.Contains(out.String(), "Commands:")
+	assert.Empty(errOut.String())
+}
+
+func TestAppNewDefaultWriters(t *testing.T) {
+	assert := assert.New(t)
+
+	app := AppNew(AppFields{Name: testAppName})
+	assert.Equal(os.Stdout, app.Out)
+	assert.Equal(os.Stderr, app.Err)
+	assert.Equal(os.Stdin, app.In)
+}
+
+func TestAppRun(t *testing.T) {
+	assert := assert.New(t)
+
+	var out bytes.Buffer
+	var errOut bytes.Buffer
+
+	appFields := AppFields{
+		Name: testAppName,
+		Out:  &out,
+		Err:  &errOut,
+	}
+
+	app := AppNew(appFields)
+
+	cmdFields := CmdFields{
+		Name:       testCmdName,
+		Deprecated: &Deprecation{},
+		ExecWithContext: func(ctx *Context) error {
+			name, err := ctx.Args.AsString("name")
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(ctx.Out, "Hello, %s!\n", name)
+			return nil
+		},
+	}
+
+	cmd, err := CmdNew(cmdFields)
+	assert.Nil(err)
+
+	arg, err := StringArgNew(ArgFields{Name: "name"})
+	assert.Nil(err)
+
+	cmd.Args.Add(arg)
+	app.Cmds.Add(cmd)
+
+	err = app.Run([]string{testAppName, testCmdName, "name=World"})
+	assert.Nil(err)
+	assert.Equal("Hello, World!\n", out.String())
+	assert.Equal("Warning: Deprecated command: myCmd.\n", errOut.String())
+
+	out.Reset()
+	err = app.Run([]string{testAppName, "help", "all"})
+	assert.Nil(err)
+	assert.Contains(out.String(), testCmdName)
+}
+
+func TestAppParseEnvChoices(t *testing.T) {
+	assert := assert.New(t)
+
+	app := AppNew(AppFields{Name: testAppName})
+
+	arg, err := StringArgNew(ArgFields{Name: "env", Env: "CLIGOBRR_TEST_ENV", Choices: []string{"dev", "prod"}})
+	assert.Nil(err)
+
+	app.Args.Add(arg)
+
+	cmd, err := CmdNew(CmdFields{Name: testCmdName, Exec: testCmdExec})
+	assert.Nil(err)
+
+	app.Cmds.Add(cmd)
+
+	t.Setenv("CLIGOBRR_TEST_ENV", "prod")
+	cmdToExec, err := app.Parse([]string{testAppName, testCmdName})
+	assert.Nil(err)
+
+	env, err := cmdToExec.App().Args.AsString("env")
+	assert.Nil(err)
+	assert.Equal("prod", env)
+
+	// With no app args typed, the environment still
+	// has to be one of the choices.
+	t.Setenv("CLIGOBRR_TEST_ENV", "prdo")
+	_, err = app.Parse([]string{testAppName, testCmdName})
+	assert.EqualError(err, "Invalid argument value: env=prdo. Did you mean: prod?")
+}
+
+func TestAppParseDoesNotLeakBetweenParses(t *testing.T) {
+	assert := assert.New(t)
+
+	app := AppNew(AppFields{Name: testAppName})
+
+	cmd, err := CmdNew(CmdFields{Name: testCmdName, Exec: testCmdExec})
+	assert.Nil(err)
+
+	arg, err := StringArgNew(ArgFields{Name: "name", Required: true})
+	assert.Nil(err)
+
+	cmd.Args.Add(arg)
+	app.Cmds.Add(cmd)
+
+	cmdToExec, err := app.Parse([]string{testAppName, testCmdName, "name=first"})
+	assert.Nil(err)
+
+	name, err := cmdToExec.Args.AsString("name")
+	assert.Nil(err)
+	assert.Equal("first", name)
+
+	_, err = app.Parse([]string{testAppName, testCmdName})
+	assert.NotNil(err)
+}
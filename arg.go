@@ -8,12 +8,21 @@ type IArg interface {
 	GetKind() string
 	GetName() string
 	GetAlias() string
+	GetAliases() []string
 	GetDescription() string
 	GetSeparator() string
 	GetMultiple() bool
 	GetRequired() bool
 	GetDefault() string
 	GetChoices() []string
+	GetPlaceholder() string
+	GetEnv() string
+	GetSection() string
+	GetHidden() bool
+	GetGlobal() bool
+	GetDeprecated() *Deprecation
+	GetDeprecatedChoices() map[string]Deprecation
+	GetComplete() FuncArgComplete
 	AsBool() (bool, error)
 	AsBools() ([]bool, error)
 	AsFloat() (float64, error)
@@ -31,12 +40,43 @@ type IArg interface {
 type ArgFields struct {
 	Name        string
 	Alias       string
+	Aliases     []string
 	Description string
 	Separator   string
 	Multiple    bool
 	Required    bool
 	Default     string
 	Choices     []string
+
+	// Placeholder names the value in usage lines, as in
+	// 'region=<name>'. It defaults to the choices or kind.
+	Placeholder string
+
+	// Env names an environment variable to take the value from
+	// when the arg isn't given. It takes priority over Default.
+	Env string
+
+	// Section groups args under a heading of the same
+	// name in help, e.g. "Connection" or "Output".
+	Section string
+
+	// Hidden args parse as usual but are left out of help.
+	Hidden bool
+
+	// Global app args can be given after the command as well as
+	// before it, as in 'myApp list output=json', unless the command
+	// has an arg of the same name. It means nothing for a command's
+	// own args.
+	Global bool
+
+	// Deprecated args, and deprecated choices (which must still be
+	// in Choices), keep working but raise a warning when used.
+	Deprecated        *Deprecation
+	DeprecatedChoices map[string]Deprecation
+
+	// Complete works out the values to offer when the arg is being
+	// completed in a shell. Without it, the choices are offered.
+	Complete FuncArgComplete
 }
 
 type Arg struct {
@@ -54,9 +94,13 @@ func argNew(fields ArgFields) (*Arg, error) {
 
 	// Make sure everything is nice and tidy.
 	fields.Alias = strings.TrimSpace(fields.Alias)
+	fields.Aliases = tidyAliases(fields.Name, "", fields.Aliases)
 	fields.Description = strings.TrimSpace(fields.Description)
 	fields.Separator = strings.TrimSpace(fields.Separator)
 	fields.Default = strings.TrimSpace(fields.Default)
+	fields.Placeholder = strings.TrimSpace(fields.Placeholder)
+	fields.Env = strings.TrimSpace(fields.Env)
+	fields.Section = strings.TrimSpace(fields.Section)
 
 	if len(fields.Choices) > 0 {
 		var choices []string
@@ -69,6 +113,18 @@ func argNew(fields ArgFields) (*Arg, error) {
 		fields.Choices = choices
 	}
 
+	if len(fields.DeprecatedChoices) > 0 {
+		deprecatedChoices := map[string]Deprecation{}
+		for choice, deprecation := range fields.DeprecatedChoices {
+			choice = strings.TrimSpace(choice)
+			if !slices.Contains(fields.Choices, choice) {
+				return nil, errDeprecatedNotAChoice(fields.Name, choice)
+			}
+			deprecatedChoices[choice] = deprecation
+		}
+		fields.DeprecatedChoices = deprecatedChoices
+	}
+
 	if fields.Multiple || len(fields.Choices) > 0 {
 		if len(fields.Separator) == 0 {
 			fields.Separator = separatorDefault
@@ -91,6 +147,23 @@ func argNew(fields ArgFields) (*Arg, error) {
 	return &arg, nil
 }
 
+// tidyAliases trims and combines a single alias with a list of them,
+// dropping blanks, duplicates, and anything that is just the name again.
+func tidyAliases(name string, alias string, aliases []string) []string {
+	var tidy []string
+
+	for _, a := range append([]string{alias}, aliases...) {
+		a = strings.TrimSpace(a)
+		if len(a) == 0 || a == name || slices.Contains(tidy, a) {
+			continue
+		}
+
+		tidy = append(tidy, a)
+	}
+
+	return tidy
+}
+
 func (self *Arg) GetKind() string {
 	return self.kind
 }
@@ -103,6 +176,12 @@ func (self *Arg) GetAlias() string {
 	return self.Alias
 }
 
+// GetAliases returns Alias followed by Aliases, so every
+// alternative name for the arg is in one place.
+func (self *Arg) GetAliases() []string {
+	return tidyAliases(self.Name, self.Alias, self.Aliases)
+}
+
 func (self *Arg) GetDescription() string {
 	return self.Description
 }
@@ -127,6 +206,38 @@ func (self *Arg) GetChoices() []string {
 	return self.Choices
 }
 
+func (self *Arg) GetPlaceholder() string {
+	return self.Placeholder
+}
+
+func (self *Arg) GetEnv() string {
+	return self.Env
+}
+
+func (self *Arg) GetSection() string {
+	return self.Section
+}
+
+func (self *Arg) GetHidden() bool {
+	return self.Hidden
+}
+
+func (self *Arg) GetGlobal() bool {
+	return self.Global
+}
+
+func (self *Arg) GetDeprecated() *Deprecation {
+	return self.Deprecated
+}
+
+func (self *Arg) GetDeprecatedChoices() map[string]Deprecation {
+	return self.DeprecatedChoices
+}
+
+func (self *Arg) GetComplete() FuncArgComplete {
+	return self.Complete
+}
+
 func (self *Arg) Store(values []string) {
 	self.values = values
 }
@@ -26,7 +26,7 @@ func (self *BoolArg) Validate() error {
 
 	for _, val := range self.values {
 		if !slices.Contains(acceptableValues, strings.ToLower(val)) {
-			return errInvalidArgValue(self.Name, val)
+			return errInvalidArgValue(self.Name, val, nil)
 		}
 	}
 
@@ -24,7 +24,7 @@ func (self *FloatArg) Validate() error {
 	for _, val := range self.values {
 		_, err := strconv.ParseFloat(val, 64)
 		if err != nil {
-			return errInvalidArgValue(self.Name, val)
+			return errInvalidArgValue(self.Name, val, nil)
 		}
 	}
 
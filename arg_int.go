@@ -24,7 +24,7 @@ func (self *IntArg) Validate() error {
 	for _, val := range self.values {
 		_, err := strconv.ParseInt(val, 10, 64)
 		if err != nil {
-			return errInvalidArgValue(self.Name, val)
+			return errInvalidArgValue(self.Name, val, nil)
 		}
 	}
 
@@ -0,0 +1,44 @@
+package cligobrr
+
+import "fmt"
+import "strings"
+
+// outputFormatArg is the output arg that App adds, which takes a
+// format's name, a template or a path. Templates and paths are
+// checked when the arg is parsed, so mistakes in them come up before
+// the command runs rather than after.
+type outputFormatArg struct {
+	StringArg
+	app *App
+}
+
+func outputFormatArgNew(app *App) IArg {
+	fields := ArgFields{
+		Name:        outputArg,
+		Description: fmt.Sprintf(outputArgDesc, strings.Join(outputFormats(), ", ")),
+		Default:     OutputTable,
+		Placeholder: outputPlaceholder,
+		Global:      true,
+
+		// There are no Choices, since templates and paths are
+		// allowed too, but the formats are still worth offering.
+		Complete: func(completion Completion) ([]string, CompleteDirective) {
+			return outputFormats(), 0
+		},
+	}
+
+	arg, _ := StringArgNew(fields)
+
+	return &outputFormatArg{StringArg: *arg.(*StringArg), app: app}
+}
+
+func (self *outputFormatArg) Validate() error {
+	for _, val := range self.values {
+		_, err := outputWriter(self.app, val)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
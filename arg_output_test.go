@@ -0,0 +1,46 @@
+package cligobrr
+
+import "testing"
+import "github.com/stretchr/testify/assert"
+
+func TestOutputFormatArg(t *testing.T) {
+	assert := assert.New(t)
+
+	arg := outputFormatArgNew(nil)
+	assert.Equal(outputArg, arg.GetName())
+	assert.Equal(OutputTable, arg.GetDefault())
+	assert.Contains(arg.GetDescription(), "table, json, yaml")
+
+	for _, valid := range []string{"json", "{{.name}}", "{.name}", "path:.name"} {
+		arg.Parse(valid)
+		assert.Nil(arg.Validate(), valid)
+	}
+
+	arg.Parse("yml")
+	assert.EqualError(arg.Validate(), "Unknown output format: yml. Did you mean: yaml?")
+
+	arg.Parse("{{.name")
+	assert.ErrorContains(arg.Validate(), "Invalid output template:")
+
+	arg.Parse("{.name[}")
+	assert.ErrorContains(arg.Validate(), "Invalid output path: missing closing ]")
+
+	clone := argClone(arg)
+	assert.Empty(clone.Stored())
+	assert.Nil(clone.Validate())
+}
+
+func TestOutputFormatArgComplete(t *testing.T) {
+	assert := assert.New(t)
+
+	app := AppNew(AppFields{Name: testAppName, Output: true, Completion: true})
+
+	candidates, _ := complete(app, []string{"output="})
+	assert.Equal([]string{
+		"output=table", "output=json", "output=yaml",
+		"output=csv", "output=tsv", "output=markdown",
+	}, candidates)
+
+	candidates, _ = complete(app, []string{"output=y"})
+	assert.Equal([]string{"output=yaml"}, candidates)
+}
@@ -53,6 +53,21 @@ func TestArgNewCleanup(t *testing.T) {
 	assert.Equal([]string{"one", "two", "three"}, arg.GetChoices())
 }
 
+func TestArgGetAliases(t *testing.T) {
+	assert := assert.New(t)
+
+	fields := ArgFields{
+		Name:    "arg",
+		Alias:   "a",
+		Aliases: []string{" argument ", "", "a", "arg", "ar"},
+	}
+
+	arg, err := argNew(fields)
+	assert.Nil(err)
+	assert.Equal("a", arg.GetAlias())
+	assert.Equal([]string{"a", "argument", "ar"}, arg.GetAliases())
+}
+
 func TestArgWithChoicesHasSeparator(t *testing.T) {
 	assert := assert.New(t)
 
@@ -444,3 +459,68 @@ func TestArgAsStringsNoValue(t *testing.T) {
 	_, err = arg.AsStrings()
 	assert.NotNil(err)
 }
+
+func TestArgClone(t *testing.T) {
+	assert := assert.New(t)
+
+	fields := ArgFields{
+		Name: "arg",
+	}
+
+	arg, err := IntArgNew(fields)
+	assert.Nil(err)
+
+	arg.Parse("123")
+
+	clone := argClone(arg)
+	assert.Equal(kindInt, clone.GetKind())
+	assert.Equal("arg", clone.GetName())
+	assert.Empty(clone.Stored())
+
+	// Validation must still be the IntArg flavour.
+	clone.Parse("not an int")
+	assert.NotNil(clone.Validate())
+	assert.Equal([]string{"123"}, arg.Stored())
+}
+
+// testEvenArg is the kind of arg someone might make of their own,
+// by embedding one of ours and adding a rule.
+type testEvenArg struct {
+	IntArg
+}
+
+func (self *testEvenArg) Validate() error {
+	for _, value := range self.values {
+		if value != "0" && value != "2" && value != "4" {
+			return errInvalidArgValue(self.Name, value, nil)
+		}
+	}
+
+	return nil
+}
+
+func TestArgCloneEmbedded(t *testing.T) {
+	assert := assert.New(t)
+
+	arg, err := IntArgNew(ArgFields{Name: "even"})
+	assert.Nil(err)
+
+	even := &testEvenArg{IntArg: *arg.(*IntArg)}
+
+	// The copy is still a testEvenArg, with its own Validate.
+	clone := argClone(even)
+	assert.IsType(&testEvenArg{}, clone)
+
+	clone.Parse("3")
+	assert.NotNil(clone.Validate())
+
+	var args Args
+	args.Add(even)
+
+	err = args.Parse([]string{"even=3"})
+	assert.NotNil(err)
+
+	err = args.Parse([]string{"even=4"})
+	assert.Nil(err)
+	assert.Equal([]string{"4"}, even.Stored())
+}
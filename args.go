@@ -1,33 +1,127 @@
 package cligobrr
 
+import "fmt"
+import "io"
+import "os"
+import "reflect"
 import "slices"
 import "strings"
 
 type Args struct {
-	args []IArg
+	args     []IArg
+	warnings []Warning
+
+	// The owner, like Cmds's, so Parse knows where
+	// warnings should go.
+	parent *Cmd
+	app    *App
+
+	// Add never fails, but Check wants to know what
+	// didn't go as the caller probably expected.
+	rejected []IArg
 }
 
 func (self *Args) Add(arg IArg) {
-	alias := strings.TrimSpace(arg.GetAlias())
+	// Every identifier the new arg answers to has to be unused,
+	// otherwise lookups would become ambiguous.
+	identifiers := append([]string{arg.GetName()}, arg.GetAliases()...)
+
+	for _, identifier := range identifiers {
+		if self.get(identifier) != nil {
+			self.rejected = append(self.rejected, arg)
+			return
+		}
+	}
 
-	nameExists := self.get(arg.GetName()) != nil
-	aliasExists := len(alias) > 0 && self.get(alias) != nil
+	self.args = append(self.args, arg)
+}
 
-	if nameExists || aliasExists {
-		return
+// Lookup returns the arg with the given name or alias,
+// or nil if there isn't one.
+func (self *Args) Lookup(identifier string) IArg {
+	arg := self.get(identifier)
+	if arg == nil {
+		return nil
 	}
 
-	self.args = append(self.args, arg)
+	return *arg
+}
+
+// List returns the args in the order they were added.
+func (self *Args) List() []IArg {
+	return slices.Clone(self.args)
+}
+
+// Remove takes the arg with the given name or alias out and
+// returns it, or returns nil if there isn't one.
+func (self *Args) Remove(identifier string) IArg {
+	i := self.index(identifier)
+	if i < 0 {
+		return nil
+	}
+
+	arg := self.args[i]
+	self.args = slices.Delete(self.args, i, i+1)
+
+	return arg
+}
+
+// Replace swaps the arg with the given name or alias for arg,
+// keeping its position, and returns the one that was replaced.
+// The new arg can't collide with any of the others.
+func (self *Args) Replace(identifier string, arg IArg) (IArg, error) {
+	i := self.index(identifier)
+	if i < 0 {
+		return nil, errArgNotFound(identifier)
+	}
+
+	old := self.args[i]
+
+	identifiers := append([]string{arg.GetName()}, arg.GetAliases()...)
+	for _, id := range identifiers {
+		j := self.index(id)
+		if j >= 0 && j != i {
+			return nil, errArgInUse(id)
+		}
+	}
+
+	self.args[i] = arg
+
+	return old, nil
+}
+
+func (self *Args) index(identifier string) int {
+	return slices.IndexFunc(self.args, func(arg IArg) bool {
+		return arg.GetName() == identifier || slices.Contains(arg.GetAliases(), identifier)
+	})
 }
 
 func (self *Args) get(identifier string) *IArg {
 	finder := func(arg IArg) bool {
-		return arg.GetName() == identifier || arg.GetAlias() == identifier
+		return arg.GetName() == identifier || slices.Contains(arg.GetAliases(), identifier)
 	}
 
 	return self.find(finder)
 }
 
+// identifiers returns the names and aliases worth suggesting
+// when something isn't recognized. Hidden args are left out,
+// since suggesting them would give them away.
+func (self *Args) identifiers() []string {
+	var identifiers []string
+
+	for _, arg := range self.args {
+		if arg.GetHidden() {
+			continue
+		}
+
+		identifiers = append(identifiers, arg.GetName())
+		identifiers = append(identifiers, arg.GetAliases()...)
+	}
+
+	return identifiers
+}
+
 func (self *Args) find(finder func(IArg) bool) *IArg {
 	for _, arg := range self.args {
 		if finder(arg) {
@@ -38,9 +132,117 @@ func (self *Args) find(finder func(IArg) bool) *IArg {
 	return nil
 }
 
+// Parse stores the values found in input on the args themselves,
+// replacing anything left over from an earlier Parse. Nothing is
+// stored unless all of input is good. Since the args are changed,
+// Parse isn't safe to call concurrently; Parsed is.
 func (self *Args) Parse(input []string) error {
+	parsed, err := self.Parsed(input)
+	if err == nil {
+		self.publish(parsed)
+	}
+
+	self.warnings = parsed.warnings
+
+	// Warnings go wherever the app's errors do, if
+	// there's an app. Standalone args only have stderr.
+	var out io.Writer = os.Stderr
+	var styles map[Style]string
+
+	app := self.owner()
+	if app != nil {
+		out = app.Err
+		styles = app.Styles
+	}
+
+	emitWarnings(out, paletteFor(out, ColorAuto, styles), self.warnings)
+
+	return err
+}
+
+// Parsed returns a copy of the args holding the values found in
+// input, leaving the args themselves alone. The copy's Warnings are
+// whatever deprecated things were used; nothing is printed.
+func (self *Args) Parsed(input []string) (Args, error) {
+	parsed := self.clone()
+
+	result := Result{}
+	err := parsed.parse(input, &result)
+
+	parsed.warnings = result.Warnings
+
+	return parsed, err
+}
+
+// Warnings returns anything deprecated that was used
+// during the most recent Parse.
+func (self *Args) Warnings() []Warning {
+	return self.warnings
+}
+
+// owner returns the app the args belong to, directly or
+// through their command, or nil if there isn't one.
+func (self *Args) owner() *App {
+	if self.app != nil {
+		return self.app
+	}
+
+	if self.parent != nil {
+		return self.parent.App()
+	}
+
+	return nil
+}
+
+// clone copies every arg definition, without any stored values,
+// so that parsing never touches the definitions themselves.
+func (self *Args) clone() Args {
+	clone := Args{
+		parent: self.parent,
+		app:    self.app,
+	}
+
+	for _, arg := range self.args {
+		clone.args = append(clone.args, argClone(arg))
+	}
+
+	return clone
+}
+
+// publish stores the values held by parsed, a clone of self, on
+// the args themselves, for the benefit of the Parse methods.
+func (self *Args) publish(parsed Args) {
+	if len(parsed.args) != len(self.args) {
+		return
+	}
+
+	for i, arg := range self.args {
+		arg.Store(parsed.args[i].Stored())
+	}
+}
+
+// argClone copies whatever type arg is, so a type that embeds one of
+// ours keeps its own methods, like Validate, in the copy. Only the
+// stored values are left behind. An arg that isn't a pointer can't
+// store anything, so it's shared as it is.
+func argClone(arg IArg) IArg {
+	value := reflect.ValueOf(arg)
+	if value.Kind() != reflect.Pointer || value.IsNil() {
+		return arg
+	}
+
+	clone := reflect.New(value.Elem().Type())
+	clone.Elem().Set(value.Elem())
+
+	copied := clone.Interface().(IArg)
+	copied.Store(nil)
+
+	return copied
+}
+
+func (self *Args) parse(input []string, result *Result) error {
 	if len(input) > len(self.args) {
-		return errUnexpectedArg(input[len(input)-1])
+		return errUnexpectedArg(input[len(input)-1], nil)
 	}
 
 	for _, pair := range input {
@@ -65,12 +267,22 @@ func (self *Args) Parse(input []string) error {
 		// does the arg exist in self.args?
 		arg := self.get(identifier)
 		if arg == nil {
-			return errUnexpectedArg(identifier)
+			return errUnexpectedArg(identifier, self.owner().suggest(identifier, self.identifiers()))
 		}
 
+		result.deprecated(deprecatedArg, (*arg).GetName(), (*arg).GetDeprecated())
+
 		// Parse the value so it gets stored.
 		(*arg).Parse(value)
 
+		deprecatedChoices := (*arg).GetDeprecatedChoices()
+		for _, val := range (*arg).Stored() {
+			if deprecation, ok := deprecatedChoices[val]; ok {
+				choice := fmt.Sprintf("%s=%s", (*arg).GetName(), val)
+				result.deprecated(deprecatedChoice, choice, &deprecation)
+			}
+		}
+
 		// Now make sure it's valid. This is separate from
 		// parsing to allow each arg sub-type its own
 		// validation rules.
@@ -80,6 +292,13 @@ func (self *Args) Parse(input []string) error {
 		}
 	} // for _, pair := range input
 
+	// Anything not given on the command line might be in the
+	// environment instead. That beats a default, so it goes first.
+	err := self.storeEnv()
+	if err != nil {
+		return err
+	}
+
 	// Make sure defaults are stored. We have to look at this after
 	// processing input because they very likely were not part of that
 	// input. ;)
@@ -88,7 +307,7 @@ func (self *Args) Parse(input []string) error {
 	// Now let's ensure stored values are acceptable. This comes after
 	// storing defaults because we want to ensure defaults are allowed,
 	// too. Tedious, I know. :(
-	err := self.validateChoices()
+	err = self.validateChoices()
 	if err != nil {
 		return err
 	}
@@ -132,7 +351,7 @@ func (self *Args) validateChoices() error {
 		// Each stored value must be a valid choice.
 		for _, val := range arg.Stored() {
 			if !slices.Contains(choices, val) {
-				return errInvalidArgValue(arg.GetName(), val)
+				return errInvalidArgValue(arg.GetName(), val, self.owner().suggest(val, choices))
 			}
 		}
 	}
@@ -140,6 +359,29 @@ func (self *Args) validateChoices() error {
 	return nil
 }
 
+func (self *Args) storeEnv() error {
+	for _, arg := range self.args {
+		env := arg.GetEnv()
+		if len(env) == 0 || len(arg.Stored()) > 0 {
+			continue
+		}
+
+		value, ok := os.LookupEnv(env)
+		if !ok || len(strings.TrimSpace(value)) == 0 {
+			continue
+		}
+
+		arg.Parse(value)
+
+		err := arg.Validate()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (self *Args) storeDefaults() {
 	for _, arg := range self.args {
 		// Do we have a default _and_ is it needed?
@@ -153,7 +395,7 @@ func (self *Args) storeDefaults() {
 func (self *Args) AsBool(identifier string) (bool, error) {
 	arg := self.get(identifier)
 	if arg == nil {
-		return false, errUnexpectedArg(identifier)
+		return false, errUnexpectedArg(identifier, nil)
 	}
 
 	return (*arg).AsBool()
@@ -162,7 +404,7 @@ func (self *Args) AsBool(identifier string) (bool, error) {
 func (self *Args) AsBools(identifier string) ([]bool, error) {
 	arg := self.get(identifier)
 	if arg == nil {
-		return nil, errUnexpectedArg(identifier)
+		return nil, errUnexpectedArg(identifier, nil)
 	}
 
 	return (*arg).AsBools()
@@ -171,7 +413,7 @@ func (self *Args) AsBools(identifier string) ([]bool, error) {
 func (self *Args) AsFloat(identifier string) (float64, error) {
 	arg := self.get(identifier)
 	if arg == nil {
-		return 0.0, errUnexpectedArg(identifier)
+		return 0.0, errUnexpectedArg(identifier, nil)
 	}
 
 	return (*arg).AsFloat()
@@ -180,7 +422,7 @@ func (self *Args) AsFloat(identifier string) (float64, error) {
 func (self *Args) AsFloats(identifier string) ([]float64, error) {
 	arg := self.get(identifier)
 	if arg == nil {
-		return nil, errUnexpectedArg(identifier)
+		return nil, errUnexpectedArg(identifier, nil)
 	}
 
 	return (*arg).AsFloats()
@@ -189,7 +431,7 @@ func (self *Args) AsFloats(identifier string) ([]float64, error) {
 func (self *Args) AsInt(identifier string) (int64, error) {
 	arg := self.get(identifier)
 	if arg == nil {
-		return 0, errUnexpectedArg(identifier)
+		return 0, errUnexpectedArg(identifier, nil)
 	}
 
 	return (*arg).AsInt()
@@ -198,7 +440,7 @@ func (self *Args) AsInt(identifier string) (int64, error) {
 func (self *Args) AsInts(identifier string) ([]int64, error) {
 	arg := self.get(identifier)
 	if arg == nil {
-		return nil, errUnexpectedArg(identifier)
+		return nil, errUnexpectedArg(identifier, nil)
 	}
 
 	return (*arg).AsInts()
@@ -207,7 +449,7 @@ func (self *Args) AsInts(identifier string) ([]int64, error) {
 func (self *Args) AsString(identifier string) (string, error) {
 	arg := self.get(identifier)
 	if arg == nil {
-		return "", errUnexpectedArg(identifier)
+		return "", errUnexpectedArg(identifier, nil)
 	}
 
 	return (*arg).AsString()
@@ -216,7 +458,7 @@ func (self *Args) AsString(identifier string) (string, error) {
 func (self *Args) AsStrings(identifier string) ([]string, error) {
 	arg := self.get(identifier)
 	if arg == nil {
-		return nil, errUnexpectedArg(identifier)
+		return nil, errUnexpectedArg(identifier, nil)
 	}
 
 	return (*arg).AsStrings()
@@ -1,6 +1,7 @@
 package cligobrr
 
 import "fmt"
+import "sync"
 import "testing"
 import "github.com/stretchr/testify/assert"
 
@@ -88,6 +89,46 @@ func TestArgParseInvalidChoice(t *testing.T) {
 	assert.NotNil(err)
 }
 
+func TestArgsAddAliases(t *testing.T) {
+	assert := assert.New(t)
+
+	var args Args
+
+	arg1Fields := ArgFields{
+		Name:    "region",
+		Aliases: []string{"r", "reg"},
+	}
+
+	arg1, err := StringArgNew(arg1Fields)
+	assert.Nil(err)
+
+	args.Add(arg1)
+	assert.NotNil(args.get("region"))
+	assert.NotNil(args.get("r"))
+	assert.NotNil(args.get("reg"))
+
+	// Collides with one of the aliases above, so it
+	// must not be added.
+	arg2Fields := ArgFields{
+		Name:    "replicas",
+		Aliases: []string{"rep", "reg"},
+	}
+
+	arg2, err := IntArgNew(arg2Fields)
+	assert.Nil(err)
+
+	args.Add(arg2)
+	assert.Nil(args.get("replicas"))
+	assert.Nil(args.get("rep"))
+	assert.Equal(1, len(args.args))
+
+	err = args.Parse([]string{"reg=eu"})
+	assert.Nil(err)
+	val, err := args.AsString("region")
+	assert.Nil(err)
+	assert.Equal("eu", val)
+}
+
 func TestArgsParseUnexpectedArg(t *testing.T) {
 	assert := assert.New(t)
 
@@ -118,6 +159,37 @@ func TestArgsParseUnexpectedArg(t *testing.T) {
 	assert.NotNil(err)
 }
 
+func TestArgsParseSuggestions(t *testing.T) {
+	assert := assert.New(t)
+
+	cmdFields := CmdFields{
+		Name: "cmd",
+	}
+
+	cmd, err := CmdNew(cmdFields)
+	assert.Nil(err)
+
+	argFields := ArgFields{
+		Name:    "region",
+		Alias:   "r",
+		Choices: []string{"us-east", "us-west", "eu-central"},
+	}
+
+	arg, err := StringArgNew(argFields)
+	assert.Nil(err)
+
+	cmd.Args.Add(arg)
+
+	err = cmd.Args.Parse([]string{"regoin=us-east"})
+	assert.EqualError(err, "Unexpected argument: regoin. Did you mean: region?")
+
+	err = cmd.Args.Parse([]string{"region=us-wset"})
+	assert.EqualError(err, "Invalid argument value: region=us-wset. Did you mean: us-west?")
+
+	err = cmd.Args.Parse([]string{"region=ap-south"})
+	assert.EqualError(err, "Invalid argument value: region=ap-south.")
+}
+
 func TestArgsParseMissingArgValue(t *testing.T) {
 	assert := assert.New(t)
 
@@ -571,3 +643,111 @@ func TestArgsAsStringsNoValue(t *testing.T) {
 	_, err = cmd.Args.AsStrings(arg.GetName())
 	assert.NotNil(err)
 }
+
+func TestArgsLookupListRemoveReplace(t *testing.T) {
+	assert := assert.New(t)
+
+	var args Args
+
+	region, _ := StringArgNew(ArgFields{Name: "region", Alias: "r"})
+	count, _ := IntArgNew(ArgFields{Name: "count", Alias: "c"})
+	args.Add(region)
+	args.Add(count)
+
+	assert.Same(region, args.Lookup("r"))
+	assert.Nil(args.Lookup("nope"))
+	assert.Equal([]IArg{region, count}, args.List())
+
+	zone, _ := StringArgNew(ArgFields{Name: "zone", Alias: "z"})
+	old, err := args.Replace("region", zone)
+	assert.Nil(err)
+	assert.Same(region, old)
+	assert.Equal([]IArg{zone, count}, args.List())
+
+	_, err = args.Replace("nope", zone)
+	assert.NotNil(err)
+
+	clash, _ := StringArgNew(ArgFields{Name: "clash", Alias: "c"})
+	_, err = args.Replace("zone", clash)
+	assert.NotNil(err)
+
+	assert.Same(count, args.Remove("count"))
+	assert.Nil(args.Remove("count"))
+	assert.Equal([]IArg{zone}, args.List())
+}
+
+func TestArgsParseEnv(t *testing.T) {
+	assert := assert.New(t)
+
+	var args Args
+
+	port, _ := IntArgNew(ArgFields{Name: "port", Env: "CLIGOBRR_TEST_PORT", Default: "80", Required: true})
+	args.Add(port)
+
+	// Nothing in the environment, so the default.
+	err := args.Parse([]string{})
+	assert.Nil(err)
+	val, _ := args.AsInt("port")
+	assert.Equal(int64(80), val)
+
+	// The environment beats the default.
+	t.Setenv("CLIGOBRR_TEST_PORT", "8080")
+	err = args.Parse([]string{})
+	assert.Nil(err)
+	val, _ = args.AsInt("port")
+	assert.Equal(int64(8080), val)
+
+	// The command line beats the environment.
+	err = args.Parse([]string{"port=443"})
+	assert.Nil(err)
+	val, _ = args.AsInt("port")
+	assert.Equal(int64(443), val)
+
+	// And the environment is validated like anything else.
+	t.Setenv("CLIGOBRR_TEST_PORT", "eighty")
+	err = args.Parse([]string{})
+	assert.NotNil(err)
+}
+
+func TestArgsParsed(t *testing.T) {
+	assert := assert.New(t)
+
+	var args Args
+
+	region, _ := StringArgNew(ArgFields{Name: "region", Deprecated: &Deprecation{}})
+	count, _ := IntArgNew(ArgFields{Name: "count"})
+	args.Add(region)
+	args.Add(count)
+
+	var wg sync.WaitGroup
+	counts := make([]int64, 20)
+
+	for i := range counts {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			parsed, err := args.Parsed([]string{fmt.Sprintf("count=%d", i)})
+			if err == nil {
+				counts[i], _ = parsed.AsInt("count")
+			}
+		}(i)
+	}
+
+	wg.Wait()
+
+	for i, count := range counts {
+		assert.Equal(int64(i), count)
+	}
+
+	parsed, err := args.Parsed([]string{"region=eu"})
+	assert.Nil(err)
+	assert.Equal([]string{"eu"}, parsed.Lookup("region").Stored())
+	assert.Equal(1, len(parsed.Warnings()))
+
+	// The args themselves are left alone.
+	assert.Empty(region.Stored())
+	assert.Empty(count.Stored())
+	assert.Empty(args.Warnings())
+}
@@ -0,0 +1,166 @@
+package cligobrr
+
+import "errors"
+import "slices"
+import "strings"
+import "text/template"
+
+// Check walks every command and arg in the app looking for things
+// that would otherwise go unnoticed: duplicate names and aliases that
+// Add quietly dropped, a second default command, commands shadowing
+// the built-in ones, required args with defaults, choices on bool
+// args, commands that have nothing to do, commands in groups that
+// weren't declared, and help templates that won't parse. Every
+// problem found is reported, not just the first.
+func (self *App) Check() error {
+	var errs []error
+
+	errs = append(errs, checkHelpTemplate(self.Name, self.HelpTemplate)...)
+	errs = append(errs, self.Args.check(self.Name)...)
+	errs = append(errs, self.Cmds.check(self.Name)...)
+
+	return errors.Join(errs...)
+}
+
+// Check is App.Check for a command and everything beneath it.
+func (self *Cmd) Check() error {
+	return errors.Join(self.check(self.Path())...)
+}
+
+func (self *Cmd) check(path string) []error {
+	var errs []error
+
+	if !self.executable() && len(self.userCmds()) == 0 {
+		errs = append(errs, errCheckNothingToExec(path))
+	}
+
+	errs = append(errs, checkHelpTemplate(path, self.HelpTemplate)...)
+
+	errs = append(errs, self.Args.check(path)...)
+	errs = append(errs, self.Cmds.check(path)...)
+
+	return errs
+}
+
+// userCmds returns the subcommands that weren't added for us.
+func (self *Cmd) userCmds() []*Cmd {
+	var cmds []*Cmd
+
+	for _, cmd := range self.Cmds.cmds {
+		if !cmd.builtin {
+			cmds = append(cmds, cmd)
+		}
+	}
+
+	return cmds
+}
+
+func (self *Cmds) check(path string) []error {
+	var errs []error
+
+	for _, cmd := range self.rejected {
+		// It may have been added since, once whatever
+		// it collided with was removed.
+		if slices.Contains(self.cmds, cmd) {
+			continue
+		}
+
+		identifiers := append([]string{cmd.Name}, cmd.GetAliases()...)
+		collided := false
+
+		for _, identifier := range identifiers {
+			existing := self.get(identifier)
+			if existing == nil {
+				continue
+			}
+
+			collided = true
+
+			if existing.builtin {
+				errs = append(errs, errCheckReservedCmd(path, identifier))
+			} else {
+				errs = append(errs, errCheckDuplicateCmd(path, identifier, existing.Name))
+			}
+		}
+
+		// Whatever it collided with has gone, but that
+		// doesn't make it part of the tree.
+		if !collided {
+			errs = append(errs, errCheckRejectedCmd(path, cmd.Name))
+		}
+	}
+
+	// A demoted command clashes with the default that beat it or,
+	// once that's gone, is left not being the default it asked to be.
+	// Making it the default again is up to whoever built the tree.
+	defCmd := self.defaultCmd()
+	for _, cmd := range self.demoted {
+		switch {
+		case cmd.Default:
+			continue
+		case defCmd != nil:
+			errs = append(errs, errCheckMultipleDefaults(path, defCmd.Name, cmd.Name))
+		default:
+			errs = append(errs, errCheckDemotedCmd(path, cmd.Name))
+		}
+	}
+
+	// Once an order is declared, a group missing from it is
+	// most likely a typo.
+	groups := self.groups()
+	for _, cmd := range self.cmds {
+		group := strings.TrimSpace(cmd.Group)
+		if len(groups) > 0 && len(group) > 0 && !slices.Contains(groups, group) {
+			errs = append(errs, errCheckUndeclaredGroup(path, cmd.Name, group))
+		}
+	}
+
+	for _, cmd := range self.cmds {
+		if cmd.builtin {
+			continue
+		}
+
+		cmdPath := strings.Join([]string{path, cmd.Name}, " ")
+		errs = append(errs, cmd.check(cmdPath)...)
+	}
+
+	return errs
+}
+
+// checkHelpTemplate only catches syntax errors. Anything else, like
+// a field that doesn't exist, won't show up until help is rendered.
+func checkHelpTemplate(path string, text string) []error {
+	_, err := template.New("help").Funcs(helpFuncs(nil, palette{}, 0)).Parse(text)
+	if err != nil {
+		return []error{errCheckHelpTemplate(path, err)}
+	}
+
+	return nil
+}
+
+func (self *Args) check(path string) []error {
+	var errs []error
+
+	for _, arg := range self.rejected {
+		identifiers := append([]string{arg.GetName()}, arg.GetAliases()...)
+
+		for _, identifier := range identifiers {
+			existing := self.get(identifier)
+			if existing != nil {
+				errs = append(errs, errCheckDuplicateArg(path, identifier, (*existing).GetName()))
+			}
+		}
+	}
+
+	for _, arg := range self.args {
+		if arg.GetRequired() && len(arg.GetDefault()) > 0 {
+			errs = append(errs, errCheckRequiredDefault(path, arg.GetName()))
+		}
+
+		if arg.GetKind() == kindBool && len(arg.GetChoices()) > 0 {
+			errs = append(errs, errCheckBoolChoices(path, arg.GetName()))
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,248 @@
+package cligobrr
+
+import "sync"
+import "testing"
+import "github.com/stretchr/testify/assert"
+
+func TestAppCheckClean(t *testing.T) {
+	assert := assert.New(t)
+
+	app := AppNew(AppFields{Name: testAppName})
+
+	cmd, err := CmdNew(CmdFields{Name: testCmdName, Alias: testCmdAlias, Exec: testCmdExec})
+	assert.Nil(err)
+
+	arg, err := StringArgNew(ArgFields{Name: testArgName, Alias: testArgAlias, Default: "x"})
+	assert.Nil(err)
+
+	cmd.Args.Add(arg)
+	app.Cmds.Add(cmd)
+
+	assert.Nil(app.Check())
+}
+
+func TestAppCheckCmds(t *testing.T) {
+	assert := assert.New(t)
+
+	app := AppNew(AppFields{Name: testAppName})
+
+	deploy, _ := CmdNew(CmdFields{Name: "deploy", Default: true, Exec: testCmdExec})
+	app.Cmds.Add(deploy)
+
+	// Alias collides with a name.
+	dup, _ := CmdNew(CmdFields{Name: "ship", Alias: "deploy", Exec: testCmdExec})
+	app.Cmds.Add(dup)
+
+	// Shadows a built-in.
+	help, _ := CmdNew(CmdFields{Name: "help", Exec: testCmdExec})
+	app.Cmds.Add(help)
+
+	// Second default.
+	status, _ := CmdNew(CmdFields{Name: "status", Default: true, Exec: testCmdExec})
+	app.Cmds.Add(status)
+
+	// Nothing to do at all.
+	empty, _ := CmdNew(CmdFields{Name: "empty"})
+	deploy.Cmds.Add(empty)
+
+	err := app.Check()
+	assert.NotNil(err)
+
+	msg := err.Error()
+	assert.Contains(msg, "Duplicate command in myApp: deploy is already used by deploy.")
+	assert.Contains(msg, "Reserved command in myApp: help is built in.")
+	assert.Contains(msg, "Multiple default commands in myApp: deploy and status.")
+	assert.Contains(msg, "Command with nothing to execute: myApp deploy empty.")
+}
+
+func TestAppCheckRemovedCmds(t *testing.T) {
+	assert := assert.New(t)
+
+	app := AppNew(AppFields{Name: testAppName})
+
+	a, _ := CmdNew(CmdFields{Name: "a", Default: true, Exec: testCmdExec})
+	b, _ := CmdNew(CmdFields{Name: "b", Default: true, Exec: testCmdExec})
+	dup, _ := CmdNew(CmdFields{Name: "c", Alias: "a", Exec: testCmdExec})
+	app.Cmds.Add(a)
+	app.Cmds.Add(b)
+	app.Cmds.Add(dup)
+
+	assert.NotNil(app.Check())
+
+	// With a gone, nothing collides with what was rejected and nothing
+	// beat b, but neither was added or made the default as asked.
+	app.Cmds.Remove("a")
+	assert.False(b.Default)
+
+	err := app.Check()
+	assert.ErrorContains(err, "Command never added in myApp: c collided with a command since removed.")
+	assert.ErrorContains(err, "Default command demoted in myApp: b lost to a default since removed.")
+
+	result, err := app.Resolve([]string{testAppName})
+	assert.Nil(err)
+	assert.Equal(ActionHelp, result.Action)
+
+	// Once they're put right, there's nothing to say.
+	b.Default = true
+	app.Cmds.Add(dup)
+	assert.Nil(app.Check())
+
+	result, err = app.Resolve([]string{testAppName})
+	assert.Nil(err)
+	assert.Same(b, result.Cmd)
+
+	// Replacing works the same way.
+	e, _ := CmdNew(CmdFields{Name: "e", Default: true, Exec: testCmdExec})
+	app.Cmds.Add(e)
+
+	d, _ := CmdNew(CmdFields{Name: "d", Exec: testCmdExec})
+	_, err = app.Cmds.Replace("b", d)
+	assert.Nil(err)
+	assert.False(e.Default)
+	assert.EqualError(app.Check(), "Default command demoted in myApp: e lost to a default since removed.")
+}
+
+func TestCmdsCheckWithoutDefault(t *testing.T) {
+	assert := assert.New(t)
+
+	app := AppNew(AppFields{Name: testAppName})
+
+	a, _ := CmdNew(CmdFields{Name: "a", Default: true, Exec: testCmdExec})
+	b, _ := CmdNew(CmdFields{Name: "b", Default: true, Exec: testCmdExec})
+	c, _ := CmdNew(CmdFields{Name: "c", Exec: testCmdExec})
+	app.Cmds.Add(a)
+	app.Cmds.Add(b)
+	app.Cmds.Add(c)
+
+	assert.EqualError(app.Check(), "Multiple default commands in myApp: a and b.")
+
+	// Demoted, but with no default left to have beaten it.
+	app.Cmds.Remove("a")
+	assert.EqualError(app.Check(), "Default command demoted in myApp: b lost to a default since removed.")
+
+	// Removing it too leaves nothing to report.
+	app.Cmds.Remove("b")
+	assert.Nil(app.Check())
+}
+
+func TestAppCheckArgs(t *testing.T) {
+	assert := assert.New(t)
+
+	app := AppNew(AppFields{Name: testAppName})
+
+	cmd, _ := CmdNew(CmdFields{Name: testCmdName, Exec: testCmdExec})
+	app.Cmds.Add(cmd)
+
+	arg1, _ := StringArgNew(ArgFields{Name: "region", Alias: "r", Required: true, Default: "eu"})
+	cmd.Args.Add(arg1)
+
+	arg2, _ := StringArgNew(ArgFields{Name: "replicas", Alias: "r"})
+	cmd.Args.Add(arg2)
+
+	arg3, _ := BoolArgNew(ArgFields{Name: "verbose", Choices: []string{"yes", "no"}})
+	app.Args.Add(arg3)
+
+	err := app.Check()
+	assert.NotNil(err)
+
+	msg := err.Error()
+	assert.Contains(msg, "Required argument with a default in myApp myCmd: region.")
+	assert.Contains(msg, "Duplicate argument in myApp myCmd: r is already used by region.")
+	assert.Contains(msg, "Bool argument with choices in myApp: verbose.")
+}
+
+func TestAppStrict(t *testing.T) {
+	assert := assert.New(t)
+
+	app := AppNew(AppFields{Name: testAppName, Strict: true})
+
+	cmd, _ := CmdNew(CmdFields{Name: testCmdName})
+	app.Cmds.Add(cmd)
+
+	_, err := app.Resolve([]string{testAppName, "version"})
+	assert.EqualError(err, "Command with nothing to execute: myApp myCmd.")
+
+	// It's checked once, so putting it right afterwards is too late.
+	cmd.Exec = testCmdExec
+
+	_, err = app.Resolve([]string{testAppName, "version"})
+	assert.EqualError(err, "Command with nothing to execute: myApp myCmd.")
+
+	app = AppNew(AppFields{Name: testAppName, Strict: true})
+	app.Cmds.Add(cmd)
+
+	_, err = app.Resolve([]string{testAppName, "version"})
+	assert.Nil(err)
+}
+
+func TestAppStrictConcurrent(t *testing.T) {
+	assert := assert.New(t)
+
+	app := AppNew(AppFields{Name: testAppName, Strict: true})
+
+	cmd, _ := CmdNew(CmdFields{Name: testCmdName})
+	app.Cmds.Add(cmd)
+
+	var wg sync.WaitGroup
+	errs := make([]error, 8)
+
+	for i := range errs {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			_, errs[i] = app.Resolve([]string{testAppName, "version"})
+		}()
+	}
+
+	wg.Wait()
+
+	for _, err := range errs {
+		assert.EqualError(err, "Command with nothing to execute: myApp myCmd.")
+	}
+}
+
+func TestCmdCheck(t *testing.T) {
+	assert := assert.New(t)
+
+	cmd, _ := CmdNew(CmdFields{Name: "deploy"})
+	assert.EqualError(cmd.Check(), "Command with nothing to execute: deploy.")
+
+	sub, _ := CmdNew(CmdFields{Name: "status", Exec: testCmdExec})
+	cmd.Cmds.Add(sub)
+	assert.Nil(cmd.Check())
+}
+
+func TestAppCheckHelpTemplate(t *testing.T) {
+	assert := assert.New(t)
+
+	app := AppNew(AppFields{Name: testAppName, HelpTemplate: "{{.Name"})
+
+	cmd, _ := CmdNew(CmdFields{Name: testCmdName, Exec: testCmdExec, HelpTemplate: "{{end}}"})
+	app.Cmds.Add(cmd)
+
+	err := app.Check()
+	assert.ErrorContains(err, "Invalid help template in myApp:")
+	assert.ErrorContains(err, "Invalid help template in myApp myCmd:")
+
+	app.HelpTemplate = `{{define "header"}}{{end}}`
+	cmd.HelpTemplate = ""
+	assert.Nil(app.Check())
+}
+
+func TestAppCheckGroups(t *testing.T) {
+	assert := assert.New(t)
+
+	app := AppNew(AppFields{Name: testAppName})
+
+	deploy, _ := CmdNew(CmdFields{Name: "deploy", Group: "Cluster", Exec: testCmdExec})
+	logs, _ := CmdNew(CmdFields{Name: "logs", Group: "Diagnostcs", Exec: testCmdExec})
+	app.Cmds.Add(deploy)
+	app.Cmds.Add(logs)
+
+	// Nothing declared, so anything goes.
+	assert.Nil(app.Check())
+
+	app.Groups = []string{"Cluster", "Diagnostics"}
+	assert.EqualError(app.Check(), "Undeclared group in myApp: logs is in Diagnostcs.")
+}
@@ -1,28 +1,85 @@
 package cligobrr
 
 import "fmt"
+import "errors"
+import "io"
+import "os"
+import "slices"
 import "strings"
 
 type FuncCmdExec func()
 type FuncCmdExecWithArgs func(args Args)
+type FuncCmdExecWithContext func(ctx *Context) error
+type FuncCmdExecWithOutput func(ctx *Context) (any, error)
+
+// CmdSort is how commands are ordered within each group in help.
+type CmdSort int
+
+const (
+	// CmdSortAdded keeps commands in the order they were added.
+	CmdSortAdded CmdSort = iota
+
+	// CmdSortName orders commands by name.
+	CmdSortName
+)
 
 type CmdFields struct {
 	Name         string
 	Alias        string
+	Aliases      []string
 	Description  string
 	Default      bool
-	Exec         FuncCmdExec
-	ExecWithArgs FuncCmdExecWithArgs
+	Group        string
+	Hidden       bool
+	Deprecated   *Deprecation
+	Examples     []Example
+	HelpTemplate string
+
+	// Groups is the order the groups of this command's subcommands
+	// are listed in help. Groups not mentioned come after these, in
+	// the order they're first used. SortCmds orders the subcommands
+	// within each group.
+	Groups   []string
+	SortCmds CmdSort
+
+	// Columns are the columns shown, in order, when ExecWithOutput
+	// returns records and neither the columns arg nor a template or
+	// path is given.
+	Columns []string
+
+	// Records is an example of what ExecWithOutput returns, like
+	// []Service{}, from which help lists the fields there are to
+	// choose from. Only its type matters.
+	Records any
+
+	Exec            FuncCmdExec
+	ExecWithArgs    FuncCmdExecWithArgs
+	ExecWithContext FuncCmdExecWithContext
+	ExecWithOutput  FuncCmdExecWithOutput
 }
 
 type Cmd struct {
 	CmdFields
-	Cmds Cmds
-	Args Args
+	Cmds     Cmds
+	Args     Args
+	warnings []Warning
+	parent   *Cmd
+	app      *App
+	builtin  bool
 }
 
+// Cmds holds commands by reference, so anything added to a command
+// after it has been added here is still part of the tree. The owner
+// is whichever Cmd or App the commands belong to.
 type Cmds struct {
-	cmds []Cmd
+	cmds   []*Cmd
+	parent *Cmd
+	app    *App
+
+	// Add never fails, but Check wants to know what
+	// didn't go as the caller probably expected.
+	rejected []*Cmd
+	demoted  []*Cmd
 }
 
 func CmdNew(fields CmdFields) (*Cmd, error) {
@@ -30,10 +87,15 @@ func CmdNew(fields CmdFields) (*Cmd, error) {
 		return nil, errNameRequired()
 	}
 
+	fields.Aliases = tidyAliases(fields.Name, "", fields.Aliases)
+
 	cmd := Cmd{
 		CmdFields: fields,
 	}
 
+	cmd.Cmds.parent = &cmd
+	cmd.Args.parent = &cmd
+
 	// We don't want to add 'help' to the 'help' and
 	// 'version' commands that get added automatically.
 	if fields.Name != "help" && fields.Name != "version" {
@@ -43,20 +105,67 @@ func CmdNew(fields CmdFields) (*Cmd, error) {
 		}
 
 		helpCmd, _ := CmdNew(helpFields)
+		helpCmd.builtin = true
 		cmd.Cmds.Add(helpCmd)
 	}
 
 	return &cmd, nil
 }
 
-func (self *Cmds) Add(cmd *Cmd) {
-	alias := strings.TrimSpace(cmd.Alias)
+// GetAliases returns Alias followed by Aliases, so every
+// alternative name for the command is in one place.
+func (self *Cmd) GetAliases() []string {
+	return tidyAliases(self.Name, self.Alias, self.Aliases)
+}
+
+// Parent returns the command this one was added to, or nil for
+// a command added directly to an App (or not added at all).
+func (self *Cmd) Parent() *Cmd {
+	return self.parent
+}
 
-	nameExists := self.get(cmd.Name) != nil
-	aliasExists := len(alias) > 0 && self.get(alias) != nil
+// App returns the App at the root of the tree this command is
+// part of, if there is one.
+func (self *Cmd) App() *App {
+	if self.app != nil {
+		return self.app
+	}
+
+	root := self
+	for root.parent != nil {
+		root = root.parent
+	}
+
+	return root.app
+}
+
+// Path returns the full command line leading to this command,
+// e.g. 'myApp deploy status'.
+func (self *Cmd) Path() string {
+	var names []string
+
+	for cmd := self; cmd != nil; cmd = cmd.parent {
+		names = append([]string{cmd.Name}, names...)
+	}
 
-	if nameExists || aliasExists {
-		return
+	app := self.App()
+	if app != nil {
+		names = append([]string{app.Name}, names...)
+	}
+
+	return strings.Join(names, " ")
+}
+
+func (self *Cmds) Add(cmd *Cmd) {
+	// Every identifier the new command answers to has to be
+	// unused, otherwise lookups would become ambiguous.
+	identifiers := append([]string{cmd.Name}, cmd.GetAliases()...)
+
+	for _, identifier := range identifiers {
+		if self.get(identifier) != nil {
+			self.rejected = append(self.rejected, cmd)
+			return
+		}
 	}
 
 	// If any other command is already default, this one
@@ -64,35 +173,309 @@ func (self *Cmds) Add(cmd *Cmd) {
 	defCmd := self.defaultCmd()
 	if cmd.Default && defCmd != nil {
 		cmd.Default = false
+		self.demoted = append(self.demoted, cmd)
+	}
+
+	cmd.parent = self.parent
+	cmd.app = self.app
+
+	self.cmds = append(self.cmds, cmd)
+}
+
+// Builtin reports whether the command was added automatically,
+// like 'help' and 'version'.
+func (self *Cmd) Builtin() bool {
+	return self.builtin
+}
+
+// Lookup returns the command with the given name or alias,
+// or nil if there isn't one.
+func (self *Cmds) Lookup(identifier string) *Cmd {
+	return self.get(identifier)
+}
+
+// List returns the commands in the order they were added.
+func (self *Cmds) List() []*Cmd {
+	return slices.Clone(self.cmds)
+}
+
+// Remove takes the command with the given name or alias out of
+// the tree and returns it, or returns nil if there isn't one.
+func (self *Cmds) Remove(identifier string) *Cmd {
+	i := self.index(identifier)
+	if i < 0 {
+		return nil
+	}
+
+	cmd := self.cmds[i]
+	self.cmds = slices.Delete(self.cmds, i, i+1)
+
+	cmd.parent = nil
+	cmd.app = nil
+
+	self.settle(cmd)
+
+	return cmd
+}
+
+// Replace swaps the command with the given name or alias for cmd,
+// keeping its position, and returns the one that was replaced. The
+// new command can't collide with any of the others.
+func (self *Cmds) Replace(identifier string, cmd *Cmd) (*Cmd, error) {
+	i := self.index(identifier)
+	if i < 0 {
+		return nil, errCmdNotFound(identifier)
+	}
+
+	old := self.cmds[i]
+
+	identifiers := append([]string{cmd.Name}, cmd.GetAliases()...)
+	for _, id := range identifiers {
+		existing := self.get(id)
+		if existing != nil && existing != old {
+			return nil, errCmdInUse(id)
+		}
+	}
+
+	if cmd.Default {
+		defCmd := self.defaultCmd()
+		if defCmd != nil && defCmd != old {
+			cmd.Default = false
+			self.demoted = append(self.demoted, cmd)
+		}
 	}
 
-	self.cmds = append(self.cmds, *cmd)
+	old.parent = nil
+	old.app = nil
+
+	cmd.parent = self.parent
+	cmd.app = self.app
+	self.cmds[i] = cmd
+
+	self.settle(old)
+
+	return old, nil
+}
+
+// settle brings the bookkeeping Check relies on up to date once gone
+// has left. Gone itself was added, so it's neither rejected nor
+// demoted. Anything rejected or demoted because of it stays that way,
+// since it was never added or made the default, and Check says so.
+func (self *Cmds) settle(gone *Cmd) {
+	isGone := func(cmd *Cmd) bool {
+		return cmd == gone
+	}
+
+	self.rejected = slices.DeleteFunc(self.rejected, isGone)
+	self.demoted = slices.DeleteFunc(self.demoted, isGone)
+}
+
+// SkipCmds can be returned from a Walk function to skip
+// everything beneath the current command.
+var SkipCmds = errors.New(msgSkipCmds)
+
+// Walk visits every command in the tree, depth first, parents before
+// children. Each call is given the path from the top of the tree
+// down to, and including, the current command. Returning SkipCmds
+// skips the current command's children; any other error stops the
+// walk and is returned.
+func (self *Cmds) Walk(fn func(path []*Cmd) error) error {
+	return self.walk(nil, fn)
+}
+
+func (self *Cmds) walk(path []*Cmd, fn func(path []*Cmd) error) error {
+	for _, cmd := range self.cmds {
+		cmdPath := append(slices.Clone(path), cmd)
+
+		err := fn(cmdPath)
+		if errors.Is(err, SkipCmds) {
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		err = cmd.Cmds.walk(cmdPath, fn)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (self *Cmds) index(identifier string) int {
+	return slices.IndexFunc(self.cmds, func(cmd *Cmd) bool {
+		return cmd.Name == identifier || slices.Contains(cmd.GetAliases(), identifier)
+	})
+}
+
+// LookupPath follows names (or aliases) down through the tree, e.g.
+// LookupPath("deploy", "status"). It returns nil if any step along
+// the way doesn't exist.
+func (self *Cmds) LookupPath(path ...string) *Cmd {
+	cmds := self
+	var cmd *Cmd
+
+	for _, identifier := range path {
+		cmd = cmds.get(identifier)
+		if cmd == nil {
+			return nil
+		}
+
+		cmds = &cmd.Cmds
+	}
+
+	return cmd
 }
 
 func (self *Cmds) get(identifier string) *Cmd {
-	finder := func(cmd Cmd) bool {
-		return cmd.Name == identifier || cmd.Alias == identifier
+	finder := func(cmd *Cmd) bool {
+		return cmd.Name == identifier || slices.Contains(cmd.GetAliases(), identifier)
 	}
 
 	return self.find(finder)
 }
 
+// groups returns the group order declared by the owner.
+func (self *Cmds) groups() []string {
+	if self.parent != nil {
+		return self.parent.Groups
+	}
+
+	if self.app != nil {
+		return self.app.Groups
+	}
+
+	return nil
+}
+
+// sortCmds returns the order the owner wants its commands in.
+func (self *Cmds) sortCmds() CmdSort {
+	if self.parent != nil {
+		return self.parent.SortCmds
+	}
+
+	if self.app != nil {
+		return self.app.SortCmds
+	}
+
+	return CmdSortAdded
+}
+
 func (self *Cmds) defaultCmd() *Cmd {
-	finder := func(cmd Cmd) bool { return cmd.Default }
+	finder := func(cmd *Cmd) bool { return cmd.Default }
 	return self.find(finder)
 }
 
-func (self *Cmds) find(finder func(Cmd) bool) *Cmd {
+// identifiers returns the names and aliases worth suggesting
+// when something isn't recognized. Hidden commands, and the
+// built-in ones the completion scripts call, are left out.
+func (self *Cmds) identifiers() []string {
+	var identifiers []string
+
+	for _, cmd := range self.cmds {
+		if cmd.Hidden || cmd.builtin {
+			continue
+		}
+
+		identifiers = append(identifiers, cmd.Name)
+		identifiers = append(identifiers, cmd.GetAliases()...)
+	}
+
+	return identifiers
+}
+
+func (self *Cmds) find(finder func(*Cmd) bool) *Cmd {
 	for _, cmd := range self.cmds {
 		if finder(cmd) {
-			return &cmd
+			return cmd
 		}
 	}
 
 	return nil
 }
 
+// Parse resolves the input and renders any help output straight
+// away, returning the command the input resolved to. Use Resolve
+// and Render when printing needs to be kept separate.
 func (self *Cmd) Parse(args []string) (*Cmd, error) {
+	self.warnings = nil
+
+	result, err := self.Resolve(args)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Cmd != nil {
+		result.Cmd.Args.publish(result.Args)
+	}
+
+	self.warnings = result.Warnings
+
+	// Warnings go wherever the app's errors do, if there's
+	// an app. A command on its own only has stderr.
+	var out io.Writer = os.Stderr
+
+	app := self.App()
+	if app != nil {
+		out = app.Err
+	}
+
+	emitWarnings(out, result.palette(out), self.warnings)
+
+	err = result.Render()
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Cmd, nil
+}
+
+// Resolve works out what the input is asking for without
+// printing anything along the way.
+func (self *Cmd) Resolve(args []string) (*Result, error) {
+	// If the command is part of an app, its writers
+	// should be used for anything rendered later.
+	result := Result{
+		app: self.App(),
+	}
+
+	if result.helpFlag(self, args) {
+		// Help is wanted, so a bad value in the
+		// environment isn't worth failing over.
+		_ = result.unparsed()
+		return &result, nil
+	}
+
+	cmd, err := self.resolve(args, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Cmd = cmd
+
+	err = result.parseAppInput()
+	if err != nil {
+		return nil, err
+	}
+
+	err = result.unparsed()
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// Warnings returns anything deprecated that was used
+// during the most recent Parse.
+func (self *Cmd) Warnings() []Warning {
+	return self.warnings
+}
+
+func (self *Cmd) resolve(args []string, result *Result) (*Cmd, error) {
 	if len(args) > 0 {
 		token := args[0]
 
@@ -101,7 +484,7 @@ func (self *Cmd) Parse(args []string) (*Cmd, error) {
 			args = args[1:]
 
 			if cmd.Name == "help" {
-				err := cmdHelp(self, args)
+				err := result.help(self, args)
 				if err != nil {
 					return nil, err
 				} else {
@@ -109,16 +492,28 @@ func (self *Cmd) Parse(args []string) (*Cmd, error) {
 				}
 			}
 
-			cmd, err := cmd.Parse(args)
+			result.deprecated(deprecatedCmd, cmd.Name, cmd.Deprecated)
+
+			cmd, err := cmd.resolve(args, result)
 			if err != nil {
 				return nil, err
 			} else {
 				return cmd, nil
 			}
 		} else {
+			// A token without '=' can't be an arg, so if it looks
+			// a lot like one of our commands, it was very likely
+			// a typo and we should say so.
+			if !strings.Contains(token, "=") {
+				suggestions := self.App().suggest(token, self.Cmds.identifiers())
+				if len(suggestions) > 0 {
+					return nil, errUnexpectedCmd(token, suggestions)
+				}
+			}
+
 			// Didn't find a command, so assume
 			// input is args for self.
-			err := self.Args.Parse(args)
+			err := self.parseArgs(args, result)
 			if err != nil {
 				return nil, err
 			}
@@ -127,7 +522,7 @@ func (self *Cmd) Parse(args []string) (*Cmd, error) {
 		}
 	}
 
-	if self.Exec == nil && self.ExecWithArgs == nil {
+	if !self.executable() {
 		// If there is nothing to execute, we need to see if
 		// there is a default command.
 
@@ -137,7 +532,7 @@ func (self *Cmd) Parse(args []string) (*Cmd, error) {
 		}
 
 		// No default command, so let's display help.
-		err := cmdHelp(self, args)
+		err := result.help(self, args)
 		if err != nil {
 			return nil, err
 		} else {
@@ -146,7 +541,7 @@ func (self *Cmd) Parse(args []string) (*Cmd, error) {
 	} else {
 		// Even though there isn't any input, we need to let
 		// Args.Parse check for required args.
-		err := self.Args.Parse(args)
+		err := self.parseArgs(args, result)
 		if err != nil {
 			return nil, err
 		}
@@ -154,3 +549,68 @@ func (self *Cmd) Parse(args []string) (*Cmd, error) {
 
 	return self, nil
 }
+
+// parseArgs parses input into a copy of the command's args, which
+// then belongs to the result. Global app args are set aside for the
+// app, unless the command has args of the same names.
+func (self *Cmd) parseArgs(input []string, result *Result) error {
+	var own []string
+
+	for _, pair := range input {
+		identifier, _, _ := strings.Cut(pair, "=")
+		identifier = strings.TrimSpace(identifier)
+
+		if self.Args.get(identifier) == nil && result.app != nil {
+			global := result.app.Args.get(identifier)
+			if global != nil && (*global).GetGlobal() {
+				result.appInput = append(result.appInput, pair)
+				continue
+			}
+		}
+
+		own = append(own, pair)
+	}
+
+	parsed := self.Args.clone()
+
+	err := parsed.parse(own, result)
+	if err != nil {
+		return err
+	}
+
+	result.Args = parsed
+
+	return nil
+}
+
+func (self *Cmd) executable() bool {
+	return self.Exec != nil || self.ExecWithArgs != nil || self.ExecWithContext != nil || self.ExecWithOutput != nil
+}
+
+// exec runs whichever exec function was given, preferring the
+// one that is handed the most.
+func (self *Cmd) exec(ctx *Context) error {
+	if self.ExecWithOutput != nil {
+		records, err := self.ExecWithOutput(ctx)
+		if err != nil {
+			return err
+		}
+
+		return ctx.Output(records)
+	}
+
+	if self.ExecWithContext != nil {
+		return self.ExecWithContext(ctx)
+	}
+
+	if self.ExecWithArgs != nil {
+		self.ExecWithArgs(ctx.Args)
+		return nil
+	}
+
+	if self.Exec != nil {
+		self.Exec()
+	}
+
+	return nil
+}
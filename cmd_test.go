@@ -1,5 +1,8 @@
 package cligobrr
 
+import "bytes"
+import "errors"
+import "strings"
 import "testing"
 import "github.com/stretchr/testify/assert"
 
@@ -74,6 +77,53 @@ func TestCmdsAdd(t *testing.T) {
 	assert.NotNil(cmd1.Cmds.get(testCmdAlias))
 }
 
+func TestCmdsAddAliases(t *testing.T) {
+	assert := assert.New(t)
+
+	var cmds Cmds
+
+	fields1 := CmdFields{
+		Name:    "remove",
+		Alias:   "rm",
+		Aliases: []string{"del", "delete"},
+	}
+
+	cmd1, err := CmdNew(fields1)
+	assert.Nil(err)
+	assert.Equal([]string{"rm", "del", "delete"}, cmd1.GetAliases())
+
+	cmds.Add(cmd1)
+	assert.NotNil(cmds.get("remove"))
+	assert.NotNil(cmds.get("rm"))
+	assert.NotNil(cmds.get("del"))
+	assert.NotNil(cmds.get("delete"))
+
+	// The name collides with an alias above.
+	fields2 := CmdFields{
+		Name: "del",
+	}
+
+	cmd2, err := CmdNew(fields2)
+	assert.Nil(err)
+
+	cmds.Add(cmd2)
+	assert.Equal("remove", cmds.get("del").Name)
+
+	// An alias collides with an alias above.
+	fields3 := CmdFields{
+		Name:    "purge",
+		Aliases: []string{"p", "rm"},
+	}
+
+	cmd3, err := CmdNew(fields3)
+	assert.Nil(err)
+
+	cmds.Add(cmd3)
+	assert.Nil(cmds.get("purge"))
+	assert.Nil(cmds.get("p"))
+	assert.Equal(1, len(cmds.cmds))
+}
+
 func TestCmdParseWithDefaultCommand(t *testing.T) {
 	assert := assert.New(t)
 
@@ -214,3 +264,247 @@ func TestCmdNewDoesNotAddHelpToVersion(t *testing.T) {
 	assert.Nil(err)
 	assert.Nil(cmd.Cmds.get("help"))
 }
+
+func TestSubCmdParseSuggestion(t *testing.T) {
+	assert := assert.New(t)
+
+	cmd1Fields := CmdFields{
+		Name: "deploy",
+	}
+
+	cmd1, err := CmdNew(cmd1Fields)
+	assert.Nil(err)
+
+	cmd2Fields := CmdFields{
+		Name: "status",
+		Exec: testCmdExec,
+	}
+
+	cmd2, err := CmdNew(cmd2Fields)
+	assert.Nil(err)
+
+	cmd1.Cmds.Add(cmd2)
+
+	cmdToExec, err := cmd1.Parse([]string{"stauts"})
+	assert.Nil(cmdToExec)
+	assert.EqualError(err, "Unexpected command: stauts. Did you mean: status?")
+}
+
+func TestCmdsAddKeepsReference(t *testing.T) {
+	assert := assert.New(t)
+
+	app := AppNew(AppFields{Name: testAppName})
+
+	deploy, err := CmdNew(CmdFields{Name: "deploy"})
+	assert.Nil(err)
+
+	// Register first, build afterwards.
+	app.Cmds.Add(deploy)
+
+	status, err := CmdNew(CmdFields{Name: "status", Exec: testCmdExec})
+	assert.Nil(err)
+
+	deploy.Cmds.Add(status)
+
+	region, err := StringArgNew(ArgFields{Name: "region"})
+	assert.Nil(err)
+
+	status.Args.Add(region)
+
+	assert.Same(deploy, app.Cmds.get("deploy"))
+
+	cmdToExec, err := app.Parse([]string{testAppName, "deploy", "status", "region=eu"})
+	assert.Nil(err)
+	assert.Same(status, cmdToExec)
+}
+
+func TestCmdPath(t *testing.T) {
+	assert := assert.New(t)
+
+	app := AppNew(AppFields{Name: testAppName})
+
+	deploy, err := CmdNew(CmdFields{Name: "deploy"})
+	assert.Nil(err)
+
+	status, err := CmdNew(CmdFields{Name: "status", Alias: "st"})
+	assert.Nil(err)
+
+	deploy.Cmds.Add(status)
+	assert.Equal("deploy status", status.Path())
+	assert.Nil(status.App())
+
+	app.Cmds.Add(deploy)
+	assert.Equal("myApp deploy status", status.Path())
+	assert.Equal("myApp deploy", deploy.Path())
+	assert.Equal("myApp deploy status help", status.Cmds.get("help").Path())
+	assert.Same(deploy, status.Parent())
+	assert.Nil(deploy.Parent())
+	assert.Same(app, status.App())
+}
+
+func TestCmdsLookupPath(t *testing.T) {
+	assert := assert.New(t)
+
+	app := AppNew(AppFields{Name: testAppName})
+
+	deploy, err := CmdNew(CmdFields{Name: "deploy", Alias: "d"})
+	assert.Nil(err)
+
+	status, err := CmdNew(CmdFields{Name: "status", Alias: "st"})
+	assert.Nil(err)
+
+	deploy.Cmds.Add(status)
+	app.Cmds.Add(deploy)
+
+	assert.Same(deploy, app.Cmds.LookupPath("deploy"))
+	assert.Same(status, app.Cmds.LookupPath("deploy", "status"))
+	assert.Same(status, app.Cmds.LookupPath("d", "st"))
+	assert.Nil(app.Cmds.LookupPath("deploy", "nope"))
+	assert.Nil(app.Cmds.LookupPath("status"))
+	assert.Nil(app.Cmds.LookupPath())
+}
+
+func TestCmdHelpUsageShowsPath(t *testing.T) {
+	assert := assert.New(t)
+
+	var out bytes.Buffer
+
+	app := AppNew(AppFields{Name: testAppName, Out: &out})
+
+	deploy, err := CmdNew(CmdFields{Name: "deploy"})
+	assert.Nil(err)
+
+	status, err := CmdNew(CmdFields{Name: "status"})
+	assert.Nil(err)
+
+	region, err := StringArgNew(ArgFields{Name: "region"})
+	assert.Nil(err)
+
+	status.Args.Add(region)
+	deploy.Cmds.Add(status)
+	app.Cmds.Add(deploy)
+
+	err = app.Run([]string{testAppName, "deploy", "status", "help"})
+	assert.Nil(err)
+	assert.Contains(out.String(), "myApp deploy status [region=<string>]")
+}
+
+func testCmdTree(t *testing.T) *App {
+	app := AppNew(AppFields{Name: testAppName})
+
+	deploy, err := CmdNew(CmdFields{Name: "deploy", Alias: "d"})
+	assert.Nil(t, err)
+
+	status, err := CmdNew(CmdFields{Name: "status", Exec: testCmdExec})
+	assert.Nil(t, err)
+
+	rollback, err := CmdNew(CmdFields{Name: "rollback", Exec: testCmdExec})
+	assert.Nil(t, err)
+
+	deploy.Cmds.Add(status)
+	deploy.Cmds.Add(rollback)
+	app.Cmds.Add(deploy)
+
+	return app
+}
+
+func TestCmdsLookupAndList(t *testing.T) {
+	assert := assert.New(t)
+
+	app := testCmdTree(t)
+
+	deploy := app.Cmds.Lookup("d")
+	assert.NotNil(deploy)
+	assert.Equal("deploy", deploy.Name)
+	assert.Nil(app.Cmds.Lookup("nope"))
+
+	var names []string
+	for _, cmd := range app.Cmds.List() {
+		names = append(names, cmd.Name)
+	}
+	assert.Equal([]string{"help", "version", "deploy"}, names)
+	assert.True(app.Cmds.List()[0].Builtin())
+	assert.False(deploy.Builtin())
+}
+
+func TestCmdsRemove(t *testing.T) {
+	assert := assert.New(t)
+
+	app := testCmdTree(t)
+
+	removed := app.Cmds.Remove("version")
+	assert.NotNil(removed)
+	assert.Nil(app.Cmds.Lookup("version"))
+	assert.Nil(app.Cmds.Remove("version"))
+
+	rollback := app.Cmds.LookupPath("deploy", "rollback")
+	removed = app.Cmds.Lookup("deploy").Cmds.Remove("rollback")
+	assert.Same(rollback, removed)
+	assert.Nil(removed.Parent())
+	assert.Equal("rollback", removed.Path())
+
+	_, err := app.Resolve([]string{testAppName, "deploy", "rollback"})
+	assert.NotNil(err)
+}
+
+func TestCmdsReplace(t *testing.T) {
+	assert := assert.New(t)
+
+	app := testCmdTree(t)
+
+	ship, err := CmdNew(CmdFields{Name: "ship", Alias: "s", Exec: testCmdExec})
+	assert.Nil(err)
+
+	old, err := app.Cmds.Replace("deploy", ship)
+	assert.Nil(err)
+	assert.Equal("deploy", old.Name)
+	assert.Same(ship, app.Cmds.List()[2])
+	assert.Equal("myApp ship", ship.Path())
+	assert.Nil(app.Cmds.Lookup("d"))
+
+	_, err = app.Cmds.Replace("nope", ship)
+	assert.NotNil(err)
+
+	// Can't collide with anything other than what's being replaced.
+	other, err := CmdNew(CmdFields{Name: "other", Alias: "help"})
+	assert.Nil(err)
+
+	_, err = app.Cmds.Replace("ship", other)
+	assert.NotNil(err)
+	assert.Same(ship, app.Cmds.Lookup("ship"))
+}
+
+func TestCmdsWalk(t *testing.T) {
+	assert := assert.New(t)
+
+	app := testCmdTree(t)
+
+	var visited []string
+	err := app.Cmds.Walk(func(path []*Cmd) error {
+		cmd := path[len(path)-1]
+		if cmd.Builtin() {
+			return SkipCmds
+		}
+
+		var names []string
+		for _, c := range path {
+			names = append(names, c.Name)
+		}
+
+		visited = append(visited, strings.Join(names, "/"))
+		return nil
+	})
+
+	assert.Nil(err)
+	assert.Equal([]string{"deploy", "deploy/status", "deploy/rollback"}, visited)
+
+	// Any other error stops the walk.
+	count := 0
+	stop := errors.New("stop")
+	err = app.Cmds.Walk(func(path []*Cmd) error {
+		count++
+		return stop
+	})
+	assert.Same(stop, err)
+	assert.Equal(1, count)
+}
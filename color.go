@@ -0,0 +1,142 @@
+package cligobrr
+
+import "fmt"
+import "io"
+import "maps"
+import "os"
+import "slices"
+
+// Style is the part a piece of text plays in help and messages,
+// which decides how it is colored.
+type Style int
+
+const (
+	StyleHeading Style = iota
+	StyleCommand
+	StyleRequired
+	StyleDefault
+	StyleWarning
+	StyleError
+)
+
+// When to use color, as given to the color arg.
+const (
+	ColorAuto   = "auto"
+	ColorAlways = "always"
+	ColorNever  = "never"
+)
+
+// styleCodes are the SGR parameters each style is drawn with,
+// unless AppFields.Styles says otherwise.
+var styleCodes = map[Style]string{
+	StyleHeading:  "1",
+	StyleCommand:  "36",
+	StyleRequired: "35",
+	StyleDefault:  "2",
+	StyleWarning:  "33",
+	StyleError:    "1;31",
+}
+
+// styleNames are how help templates refer to styles, as in
+// {{ style "heading" "Usage:" }}.
+var styleNames = map[string]Style{
+	"heading":  StyleHeading,
+	"command":  StyleCommand,
+	"required": StyleRequired,
+	"default":  StyleDefault,
+	"warning":  StyleWarning,
+	"error":    StyleError,
+}
+
+// palette colors text, or leaves it alone when color is off, which is
+// what the zero palette does.
+type palette struct {
+	codes map[Style]string
+}
+
+// paletteFor decides whether what is written to out gets colored.
+// Styles overrides the default codes.
+func paletteFor(out io.Writer, mode string, styles map[Style]string) palette {
+	if !colorEnabled(out, mode) {
+		return palette{}
+	}
+
+	codes := maps.Clone(styleCodes)
+	maps.Copy(codes, styles)
+
+	return palette{codes: codes}
+}
+
+// colorEnabled follows the color arg when it says always or never.
+// Otherwise, there's color only when out is a terminal and nobody
+// has asked for there not to be (https://no-color.org).
+func colorEnabled(out io.Writer, mode string) bool {
+	switch mode {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+
+	if len(os.Getenv("NO_COLOR")) > 0 || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+
+	return terminalCheck(out)
+}
+
+// colorMode is the value of the color arg, if there is one.
+func colorMode(args *Args) string {
+	arg := args.Lookup(colorArg)
+	if arg != nil && len(arg.Stored()) > 0 {
+		return arg.Stored()[0]
+	}
+
+	return ColorAuto
+}
+
+func (self palette) paint(style Style, text string) string {
+	code := self.codes[style]
+	if len(code) == 0 || len(text) == 0 {
+		return text
+	}
+
+	return fmt.Sprintf("\x1b[%sm%s\x1b[0m", code, text)
+}
+
+// style is paint for templates, which know styles by name. A name
+// it doesn't know gets suggestions as app would make them.
+func (self palette) style(app *App, name string, text string) (string, error) {
+	style, ok := styleNames[name]
+	if !ok {
+		var names []string
+		for known := range styleNames {
+			names = append(names, known)
+		}
+
+		slices.Sort(names)
+
+		return "", errUnknownStyle(name, app.suggest(name, names))
+	}
+
+	return self.paint(style, text), nil
+}
+
+// headings is a Table's Paint for styling its headers.
+func (self palette) headings(row int, col int, cell string) string {
+	if row < 0 {
+		return self.paint(StyleHeading, cell)
+	}
+
+	return cell
+}
+
+// labels is a Table's Paint for styling the first column
+// of a table of labels and values.
+func (self palette) labels(row int, col int, cell string) string {
+	if col == 0 {
+		return self.paint(StyleHeading, cell)
+	}
+
+	return cell
+}
@@ -0,0 +1,130 @@
+package cligobrr
+
+import "bytes"
+import "io"
+import "strings"
+import "testing"
+import "github.com/stretchr/testify/assert"
+
+func TestColorEnabled(t *testing.T) {
+	assert := assert.New(t)
+
+	defer func(check func(io.Writer) bool) { terminalCheck = check }(terminalCheck)
+	terminalCheck = func(io.Writer) bool { return true }
+
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("TERM", "xterm")
+
+	var out bytes.Buffer
+
+	assert.True(colorEnabled(&out, ColorAuto))
+	assert.True(colorEnabled(&out, ColorAlways))
+	assert.False(colorEnabled(&out, ColorNever))
+
+	t.Setenv("NO_COLOR", "1")
+	assert.False(colorEnabled(&out, ColorAuto))
+	assert.True(colorEnabled(&out, ColorAlways))
+
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("TERM", "dumb")
+	assert.False(colorEnabled(&out, ColorAuto))
+
+	t.Setenv("TERM", "xterm")
+	terminalCheck = func(io.Writer) bool { return false }
+	assert.False(colorEnabled(&out, ColorAuto))
+
+	// A buffer is never a terminal.
+	assert.False(isTerminal(&out))
+}
+
+func TestPalette(t *testing.T) {
+	assert := assert.New(t)
+
+	var out bytes.Buffer
+
+	plain := paletteFor(&out, ColorNever, nil)
+	assert.Equal("Usage:", plain.paint(StyleHeading, "Usage:"))
+
+	colors := paletteFor(&out, ColorAlways, map[Style]string{StyleCommand: "1;34", StyleDefault: ""})
+	assert.Equal("\x1b[1mUsage:\x1b[0m", colors.paint(StyleHeading, "Usage:"))
+	assert.Equal("\x1b[1;34mdeploy\x1b[0m", colors.paint(StyleCommand, "deploy"))
+	assert.Equal("none", colors.paint(StyleDefault, "none"))
+	assert.Equal("", colors.paint(StyleHeading, ""))
+
+	// Overriding one app's styles leaves everyone else's alone.
+	assert.Equal("36", styleCodes[StyleCommand])
+
+	styled, err := colors.style(nil, "required", "host")
+	assert.Nil(err)
+	assert.Equal("\x1b[35mhost\x1b[0m", styled)
+
+	_, err = colors.style(nil, "heding", "Usage:")
+	assert.EqualError(err, "Unknown style: heding. Did you mean: heading?")
+}
+
+func TestHelpColor(t *testing.T) {
+	assert := assert.New(t)
+
+	var out bytes.Buffer
+
+	app := AppNew(AppFields{Name: testAppName, Out: &out, Color: true})
+
+	cmd, _ := CmdNew(CmdFields{Name: "ssh", Exec: testCmdExec})
+	host, _ := StringArgNew(ArgFields{Name: "host", Required: true})
+	port, _ := IntArgNew(ArgFields{Name: "port", Default: "22"})
+	cmd.Args.Add(host)
+	cmd.Args.Add(port)
+	app.Cmds.Add(cmd)
+
+	assert.Nil(app.Run([]string{testAppName, "color=never", "ssh", "help"}))
+	plain := out.String()
+	assert.NotContains(plain, "\x1b[")
+
+	out.Reset()
+	assert.Nil(app.Run([]string{testAppName, "color=always", "ssh", "help"}))
+	colored := out.String()
+
+	assert.Contains(colored, "\x1b[1mName:\x1b[0m")
+	assert.Contains(colored, "\x1b[1mUsage:\x1b[0m")
+	assert.Contains(colored, "\x1b[1mArguments:\x1b[0m")
+	assert.Contains(colored, "\x1b[1mCommands:\x1b[0m")
+	assert.Contains(colored, "\x1b[35mhost\x1b[0m")
+	assert.Contains(colored, "\x1b[2m22\x1b[0m")
+	assert.Contains(colored, "\x1b[36mhelp\x1b[0m")
+
+	// Color doesn't move anything: without the escapes,
+	// it's exactly what was shown without color.
+	assert.Equal(plain, stripANSI(colored))
+
+	// Help and warnings are plain when not going to a terminal.
+	out.Reset()
+	assert.Nil(app.Run([]string{testAppName, "ssh", "help"}))
+	assert.Equal(plain, out.String())
+}
+
+func TestWarningColor(t *testing.T) {
+	var out bytes.Buffer
+
+	warnings := []Warning{{Kind: deprecatedCmd, Name: "rm"}}
+
+	emitWarnings(&out, paletteFor(&out, ColorAlways, nil), warnings)
+	assert.Equal(t, "\x1b[33mWarning: Deprecated command: rm.\x1b[0m\n", out.String())
+}
+
+func TestPrintError(t *testing.T) {
+	assert := assert.New(t)
+
+	var out bytes.Buffer
+
+	app := AppNew(AppFields{Name: testAppName, Err: &out, Color: true})
+	app.PrintError(errUnknownStyle("x", nil))
+	assert.Equal("Unknown style: x.\n", out.String())
+
+	out.Reset()
+	_, err := app.Parse([]string{testAppName, "color=always", "version"})
+	assert.Nil(err)
+
+	app.PrintError(errUnknownStyle("x", nil))
+	assert.Equal("\x1b[1;31mUnknown style: x.\x1b[0m\n", out.String())
+	assert.True(strings.HasSuffix(out.String(), "\n"))
+}
@@ -0,0 +1,266 @@
+package cligobrr
+
+import "fmt"
+import "io"
+import "os"
+import "strings"
+
+// CompleteDirective tells the shell what to do with the completions
+// it has been given. Directives can be combined.
+type CompleteDirective int
+
+const (
+	// CompleteNoSpace leaves the cursor right after the completion
+	// rather than adding a space, for values that go on.
+	CompleteNoSpace CompleteDirective = 1 << iota
+
+	// CompleteFiles has the shell complete file names itself.
+	// Any completions given alongside it are ignored.
+	CompleteFiles
+
+	// CompleteDirs is CompleteFiles, but for directories only.
+	CompleteDirs
+)
+
+// Completion is everything an arg's completion callback has to go
+// on. AppArgs and Args hold whatever has been typed for the app and
+// for Cmd so far, along with anything from the environment and the
+// defaults. They are parsed as well as they can be; a command line
+// still being typed is rarely valid, so nothing there is reported.
+type Completion struct {
+	// Partial is as much of the value as has been typed. For an
+	// arg taking several values, that includes those before the
+	// last separator, though only the last one gets completed.
+	Partial string
+
+	// Cmd is the command whose arg is being completed,
+	// or nil when it is one of the app's.
+	Cmd *Cmd
+
+	AppArgs Args
+	Args    Args
+}
+
+// FuncArgComplete returns the values an arg could take, given what
+// has been typed so far. They don't need to be filtered by Partial
+// unless the callback wants to; the shell does that anyway.
+type FuncArgComplete func(completion Completion) ([]string, CompleteDirective)
+
+// complete works out what could come next on a command line. Words
+// are what was typed after the app's name, the last of them being
+// the one the cursor is on, which may well be empty.
+func complete(app *App, words []string) ([]string, CompleteDirective) {
+	current := ""
+	if len(words) > 0 {
+		current = words[len(words)-1]
+		words = words[:len(words)-1]
+	}
+
+	// App args come first, then the commands, then the args of the
+	// last command. Once a command has args, nothing else can follow.
+	var cmd *Cmd
+	var appInput, cmdInput []string
+
+	for _, word := range words {
+		if strings.Contains(word, "=") {
+			if cmd == nil {
+				appInput = append(appInput, word)
+			} else {
+				cmdInput = append(cmdInput, word)
+			}
+
+			continue
+		}
+
+		if len(cmdInput) > 0 {
+			break
+		}
+
+		next := completeCmds(app, cmd).get(word)
+		if next != nil {
+			cmd = next
+		}
+	}
+
+	args := &app.Args
+	if cmd != nil {
+		args = &cmd.Args
+	}
+
+	name, partial, ok := strings.Cut(current, "=")
+	if ok {
+		arg := args.Lookup(strings.TrimSpace(name))
+		if arg == nil {
+			return nil, 0
+		}
+
+		values := currentChoices(arg)
+		var directive CompleteDirective
+
+		callback := arg.GetComplete()
+		if callback != nil {
+			completion := Completion{
+				Partial: partial,
+				Cmd:     cmd,
+				AppArgs: completeArgs(&app.Args, appInput),
+			}
+
+			if cmd != nil {
+				completion.Args = completeArgs(&cmd.Args, cmdInput)
+			}
+
+			values, directive = callback(completion)
+		}
+
+		// Only the last of several values is being typed, so
+		// that's what's completed. The rest are kept as they are.
+		given := ""
+		if arg.GetMultiple() {
+			i := strings.LastIndex(partial, arg.GetSeparator())
+			if i >= 0 {
+				given = partial[:i+len(arg.GetSeparator())]
+				partial = partial[i+len(arg.GetSeparator()):]
+			}
+		}
+
+		var candidates []string
+		for _, value := range values {
+			if strings.HasPrefix(value, partial) {
+				candidates = append(candidates, name+"="+given+value)
+			}
+		}
+
+		return candidates, directive
+	}
+
+	input := appInput
+	if cmd != nil {
+		input = cmdInput
+	}
+
+	var candidates []string
+
+	if len(cmdInput) == 0 {
+		for _, c := range visibleCmds(completeCmds(app, cmd).cmds, false) {
+			candidates = append(candidates, c.Name)
+			candidates = append(candidates, c.GetAliases()...)
+		}
+	}
+
+	for _, arg := range visibleArgs(args.args, false) {
+		// An arg only takes one value, or one list of them.
+		if !arg.GetMultiple() && completeGiven(args, input, arg) {
+			continue
+		}
+
+		candidates = append(candidates, arg.GetName()+"=")
+	}
+
+	var matches []string
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, current) {
+			matches = append(matches, candidate)
+		}
+	}
+
+	// Leave the cursor right after an arg's =, ready for its value.
+	if len(matches) == 1 && strings.HasSuffix(matches[0], "=") {
+		return matches, CompleteNoSpace
+	}
+
+	return matches, 0
+}
+
+// completeCmds treats a nil command as the app itself.
+func completeCmds(app *App, cmd *Cmd) *Cmds {
+	if cmd == nil {
+		return &app.Cmds
+	}
+
+	return &cmd.Cmds
+}
+
+// completeGiven is whether arg appears in input, by any of its names.
+func completeGiven(args *Args, input []string, arg IArg) bool {
+	for _, pair := range input {
+		identifier, _, _ := strings.Cut(pair, "=")
+
+		given := args.get(strings.TrimSpace(identifier))
+		if given != nil && *given == arg {
+			return true
+		}
+	}
+
+	return false
+}
+
+// completeArgs parses as much of input as it can into a copy of args,
+// skipping anything that doesn't make sense yet rather than failing.
+func completeArgs(args *Args, input []string) Args {
+	parsed := args.clone()
+
+	for _, pair := range input {
+		identifier, value, _ := strings.Cut(pair, "=")
+
+		arg := parsed.get(strings.TrimSpace(identifier))
+		if arg != nil {
+			completeValue(*arg, value)
+		}
+	}
+
+	// The same goes for the environment, which storeEnv
+	// would give up on at the first bad value.
+	for _, arg := range parsed.args {
+		env := arg.GetEnv()
+		if len(env) > 0 && len(arg.Stored()) == 0 {
+			completeValue(arg, os.Getenv(env))
+		}
+	}
+
+	parsed.storeDefaults()
+
+	return parsed
+}
+
+func completeValue(arg IArg, value string) {
+	value = strings.TrimSpace(value)
+	if len(value) == 0 {
+		return
+	}
+
+	arg.Parse(value)
+
+	if arg.Validate() != nil {
+		arg.Store(nil)
+	}
+}
+
+// writeCompletions writes what complete found, one per line, and then
+// the directive on a line of its own, prefixed with a colon. That's
+// what the scripts WriteCompletion makes expect to read back.
+func writeCompletions(out io.Writer, app *App, words []string) error {
+	candidates, directive := complete(app, words)
+
+	for _, candidate := range candidates {
+		_, err := fmt.Fprintln(out, candidate)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprintf(out, ":%d\n", directive)
+	return err
+}
+
+// completeCmdNew makes the hidden command the completion scripts call.
+func completeCmdNew() *Cmd {
+	cmd, _ := CmdNew(CmdFields{
+		Name:        completeName,
+		Description: completeDesc,
+		Hidden:      true,
+	})
+
+	cmd.builtin = true
+
+	return cmd
+}
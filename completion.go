@@ -0,0 +1,127 @@
+package cligobrr
+
+import _ "embed"
+import "fmt"
+import "io"
+import "slices"
+import "strings"
+import "text/template"
+
+// The shells completion scripts can be written for.
+const (
+	ShellBash       = "bash"
+	ShellZsh        = "zsh"
+	ShellFish       = "fish"
+	ShellPowerShell = "powershell"
+)
+
+func completionShells() []string {
+	return []string{ShellBash, ShellZsh, ShellFish, ShellPowerShell}
+}
+
+//go:embed completion.tmpl
+var completionTemplateText string
+
+var completionTemplate = template.Must(template.New("completion").Funcs(template.FuncMap{
+	"sh":   completionQuoteSh,
+	"fish": completionQuoteFish,
+	"ps":   completionQuotePowerShell,
+}).Parse(completionTemplateText))
+
+// WriteCompletion writes a script that teaches shell, one of bash,
+// zsh, fish or powershell, to complete the app's commands, args and
+// choices. Hidden and deprecated things are left out, as they are
+// from help.
+func (self *App) WriteCompletion(w io.Writer, shell string) error {
+	if !slices.Contains(completionShells(), shell) {
+		return errUnknownShell(shell, self.suggest(shell, completionShells()))
+	}
+
+	return completionTemplate.ExecuteTemplate(w, shell, completionModelOf(self))
+}
+
+// completionModel is what the completion scripts are made from.
+// The scripts know nothing about the app itself; they hand the words
+// typed so far to the app's Complete command and offer whatever comes
+// back.
+type completionModel struct {
+	Name string
+
+	// Func is the name of the script's main function, made from
+	// the app's name so that scripts for different apps don't get
+	// in each other's way.
+	Func string
+
+	// Complete is the name of the hidden command that does the
+	// completing, and the directives it can answer with.
+	Complete string
+	NoSpace  CompleteDirective
+	Files    CompleteDirective
+	Dirs     CompleteDirective
+}
+
+func completionModelOf(app *App) completionModel {
+	return completionModel{
+		Name:     app.Name,
+		Func:     completionFunc(app.Name),
+		Complete: completeName,
+		NoSpace:  CompleteNoSpace,
+		Files:    CompleteFiles,
+		Dirs:     CompleteDirs,
+	}
+}
+
+// completionFunc turns name into something every shell will accept
+// as the name of a function.
+func completionFunc(name string) string {
+	var b strings.Builder
+
+	b.WriteString("_")
+
+	for _, r := range name {
+		if r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('_')
+		}
+	}
+
+	b.WriteString("_complete")
+
+	return b.String()
+}
+
+func completionQuoteSh(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func completionQuoteFish(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(s) + "'"
+}
+
+func completionQuotePowerShell(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// completionCmdNew makes the completion command, with a subcommand
+// for each shell, that AppFields.Completion adds.
+func completionCmdNew(app *App) *Cmd {
+	completion, _ := CmdNew(CmdFields{
+		Name:        completionName,
+		Description: completionDesc,
+	})
+
+	for _, shell := range completionShells() {
+		cmd, _ := CmdNew(CmdFields{
+			Name:        shell,
+			Description: fmt.Sprintf(completionShellDesc, shell),
+			ExecWithContext: func(ctx *Context) error {
+				return app.WriteCompletion(ctx.Out, shell)
+			},
+		})
+
+		completion.Cmds.Add(cmd)
+	}
+
+	return completion
+}
@@ -0,0 +1,251 @@
+package cligobrr
+
+import "bytes"
+import "os"
+import "os/exec"
+import "path/filepath"
+import "strings"
+import "testing"
+import "github.com/stretchr/testify/assert"
+
+func testCompletionApp(out *bytes.Buffer) *App {
+	app := AppNew(AppFields{Name: testAppName, Out: out, Completion: true})
+
+	deploy, _ := CmdNew(CmdFields{Name: "deploy", Alias: "d", Exec: testCmdExec})
+	env, _ := StringArgNew(ArgFields{Name: "env", Alias: "e", Choices: []string{"staging", "prod"}})
+	secret, _ := StringArgNew(ArgFields{Name: "secret", Hidden: true})
+	regions, _ := StringArgNew(ArgFields{Name: "regions", Multiple: true, Choices: []string{"us", "eu", "ap"}})
+	deploy.Args.Add(env)
+	deploy.Args.Add(secret)
+	deploy.Args.Add(regions)
+
+	status, _ := CmdNew(CmdFields{Name: "status", Exec: testCmdExec})
+	deploy.Cmds.Add(status)
+
+	internal, _ := CmdNew(CmdFields{Name: "internal", Hidden: true, Exec: testCmdExec})
+
+	// Clusters depend on the env, which has to have been typed first.
+	clusters := func(c Completion) ([]string, CompleteDirective) {
+		env, _ := c.Args.AsString("env")
+		return []string{env + "-east", env + "-west"}, 0
+	}
+
+	files := func(c Completion) ([]string, CompleteDirective) {
+		return nil, CompleteFiles
+	}
+
+	connect, _ := CmdNew(CmdFields{Name: "connect", Exec: testCmdExec})
+	cluster, _ := StringArgNew(ArgFields{Name: "cluster", Complete: clusters})
+	connectEnv, _ := StringArgNew(ArgFields{Name: "env", Default: "dev"})
+	file, _ := StringArgNew(ArgFields{Name: "file", Complete: files})
+	connect.Args.Add(cluster)
+	connect.Args.Add(connectEnv)
+	connect.Args.Add(file)
+
+	app.Cmds.Add(deploy)
+	app.Cmds.Add(internal)
+	app.Cmds.Add(connect)
+
+	return app
+}
+
+func TestComplete(t *testing.T) {
+	assert := assert.New(t)
+
+	app := testCompletionApp(&bytes.Buffer{})
+
+	candidates := func(words ...string) []string {
+		candidates, _ := complete(app, words)
+		return candidates
+	}
+
+	assert.Equal([]string{"help", "version", "completion", "deploy", "d", "connect"}, candidates(""))
+	assert.Equal([]string{"deploy", "d"}, candidates("d"))
+	assert.Equal([]string{"help", "status", "env=", "regions="}, candidates("deploy", ""))
+	assert.Equal([]string{"e=staging", "e=prod"}, candidates("d", "e="))
+	assert.Equal([]string{"env=prod"}, candidates("deploy", "env=p"))
+	assert.Equal([]string{"regions="}, candidates("deploy", "env=prod", ""))
+	assert.Equal([]string{"regions="}, candidates("deploy", "e=prod", "re"))
+	assert.Equal([]string{"cluster=", "file="}, candidates("connect", "env=prod", ""))
+
+	// Args taking several values go on being offered, and
+	// only the value after the last separator is completed.
+	assert.Equal([]string{"env=", "regions="}, candidates("deploy", "regions=us", ""))
+	assert.Equal([]string{"regions=us,eu"}, candidates("deploy", "regions=us,e"))
+	assert.Equal([]string{"regions=us,us", "regions=us,eu", "regions=us,ap"}, candidates("deploy", "regions=us,"))
+	assert.Nil(candidates("in"))
+	assert.Nil(candidates("deploy", "nope="))
+
+	// Callbacks see what has been typed, and the defaults.
+	assert.Equal([]string{"cluster=dev-east", "cluster=dev-west"}, candidates("connect", "cluster="))
+	assert.Equal([]string{"cluster=prod-west"}, candidates("connect", "env=prod", "cluster=prod-w"))
+
+	var got Completion
+	cluster := *app.Cmds.get("connect").Args.get("cluster")
+	cluster.(*StringArg).Complete = func(c Completion) ([]string, CompleteDirective) {
+		got = c
+		return nil, 0
+	}
+
+	candidates("connect", "file=a", "env=", "cluster=x")
+	assert.Equal("x", got.Partial)
+	assert.Equal("connect", got.Cmd.Name)
+	assert.Equal([]string{"a"}, got.Args.Lookup("file").Stored())
+	assert.Equal([]string{"dev"}, got.Args.Lookup("env").Stored())
+
+	_, directive := complete(app, []string{"connect", "file="})
+	assert.Equal(CompleteFiles, directive)
+
+	_, directive = complete(app, []string{"deploy", "e"})
+	assert.Equal(CompleteNoSpace, directive)
+}
+
+func TestCompleteCmd(t *testing.T) {
+	assert := assert.New(t)
+
+	var out bytes.Buffer
+
+	app := testCompletionApp(&out)
+
+	// Even input that wouldn't resolve, or asks for help, is completed.
+	assert.Nil(app.Run([]string{testAppName, "__complete", "deploy", "--help", "nope", "e"}))
+	assert.Equal("env=\n:1\n", out.String())
+
+	out.Reset()
+	assert.Nil(app.Run([]string{testAppName, "__complete", "connect", "file=sr"}))
+	assert.Equal(":2\n", out.String())
+
+	result, err := app.Resolve([]string{testAppName, "__complete", "d"})
+	assert.Nil(err)
+	assert.Equal(ActionComplete, result.Action)
+	assert.Equal([]string{"d"}, result.Complete)
+
+	out.Reset()
+	assert.Nil(app.Run([]string{testAppName, "help"}))
+	assert.NotContains(out.String(), "__complete")
+}
+
+func TestWriteCompletion(t *testing.T) {
+	assert := assert.New(t)
+
+	var out bytes.Buffer
+
+	app := testCompletionApp(&out)
+
+	for _, shell := range completionShells() {
+		out.Reset()
+		assert.Nil(app.Run([]string{testAppName, "completion", shell}))
+
+		script := out.String()
+		assert.Contains(script, "'myApp'")
+		assert.Contains(script, "__complete")
+	}
+
+	err := app.WriteCompletion(&out, "bsh")
+	assert.EqualError(err, "Unknown shell: bsh. Did you mean: bash, zsh?")
+}
+
+func TestCompletionQuote(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(`'it'\''s'`, completionQuoteSh("it's"))
+	assert.Equal(`'it\'s \\'`, completionQuoteFish(`it's \`))
+	assert.Equal(`'it''s'`, completionQuotePowerShell("it's"))
+	assert.Equal("_my_app_v2_complete", completionFunc("my-app.v2"))
+}
+
+// TestCompletionHelper stands in for the app when the completion
+// scripts are run for real. It does nothing unless one of those
+// tests has asked it to.
+func TestCompletionHelper(t *testing.T) {
+	if os.Getenv("CLIGOBRR_COMPLETION_HELPER") != "1" {
+		t.Skip("only run by the completion script tests")
+	}
+
+	args := os.Args
+	for i, arg := range args {
+		if arg == "--" {
+			args = args[i+1:]
+			break
+		}
+	}
+
+	var out bytes.Buffer
+
+	app := testCompletionApp(&out)
+	err := app.Run(append([]string{testAppName}, args...))
+
+	os.Stdout.Write(out.Bytes())
+
+	if err != nil {
+		os.Exit(1)
+	}
+
+	os.Exit(0)
+}
+
+// TestCompletionBash runs the bash script, when there's a bash to run
+// it with, much as bash itself would on pressing tab. The app is this
+// test binary, running TestCompletionHelper.
+func TestCompletionBash(t *testing.T) {
+	assert := assert.New(t)
+
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash isn't installed")
+	}
+
+	var out bytes.Buffer
+
+	app := testCompletionApp(&out)
+	assert.Nil(app.WriteCompletion(&out, ShellBash))
+
+	dir := t.TempDir()
+
+	script := filepath.Join(dir, "completion.bash")
+	assert.Nil(os.WriteFile(script, out.Bytes(), 0o644))
+
+	assert.Nil(os.WriteFile(filepath.Join(dir, "readme.md"), nil, 0o644))
+	assert.Nil(os.Mkdir(filepath.Join(dir, "src"), 0o755))
+
+	driver := `
+		source "$1"
+		helper="$2"
+		myApp() { CLIGOBRR_COMPLETION_HELPER=1 "$helper" -test.run='^TestCompletionHelper$' -- "$@"; }
+		cd "$3"
+		COMP_WORDBREAKS="$5"
+		COMP_LINE="$4"
+		COMP_POINT=${#COMP_LINE}
+		_myApp_complete 2>/dev/null
+		echo "${COMPREPLY[*]}"
+	`
+
+	complete := func(line string, wordbreaks string) string {
+		cmd := exec.Command(bash, "-c", driver, "bash", script, os.Args[0], dir, line, wordbreaks)
+
+		output, err := cmd.Output()
+		assert.Nil(err)
+
+		return strings.TrimSpace(string(output))
+	}
+
+	// Bash splits words at = by default.
+	wordbreaks := " \t\n\"'><=;|&(:"
+
+	assert.Equal("help version completion deploy d connect", complete("myApp ", wordbreaks))
+	assert.Equal("deploy d", complete("myApp d", wordbreaks))
+	assert.Equal("help status env= regions=", complete("myApp deploy ", wordbreaks))
+	assert.Equal("env=", complete("myApp d e", wordbreaks))
+	assert.Equal("staging prod", complete("myApp deploy env=", wordbreaks))
+	assert.Equal("prod", complete("myApp d e=p", wordbreaks))
+	assert.Equal("regions=", complete("myApp deploy env=prod ", wordbreaks))
+	assert.Equal("status", complete("myApp deploy s", wordbreaks))
+	assert.Equal("", complete("myApp in", wordbreaks))
+	assert.Equal("us,eu", complete("myApp deploy regions=us,e", wordbreaks))
+	assert.Equal("prod-east prod-west", complete("myApp connect env=prod cluster=", wordbreaks))
+	assert.Equal("readme.md", complete("myApp connect file=r", wordbreaks))
+
+	// Without =, the whole word is being completed.
+	assert.Equal("e=prod", complete("myApp d e=p", " "))
+	assert.Equal("file=src", complete("myApp connect file=s", " "))
+}
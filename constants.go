@@ -24,9 +24,110 @@ const (
 	msgDefaultNotAValidChoice = "Default value is not a valid choice: %s."
 	msgTableColsRequired      = "Table columns is required."
 	msgTableRowIncorrectCols  = "Table row must contain %d columns."
+	msgDidYouMean             = "Did you mean: %s?"
+	msgArgNotFound            = "Argument not found: %s."
+	msgCmdNotFound            = "Command not found: %s."
+	msgArgInUse               = "Argument identifier already in use: %s."
+	msgCmdInUse               = "Command identifier already in use: %s."
+	msgSkipCmds               = "Skip commands."
+	msgHelpTemplate           = "Invalid help template: %s"
+	msgUnknownColumn          = "Unknown column: %s."
+	msgUnknownOutputFormat    = "Unknown output format: %s."
+	msgUnknownStyle           = "Unknown style: %s."
+	msgUnknownShell           = "Unknown shell: %s."
+	msgUnsupportedOutput      = "Can't output records of kind %s."
+	msgOutputTemplate         = "Invalid output template: %s"
+	msgOutputTemplateFields   = "Fields: %s."
+	msgOutputPath             = "Invalid output path: %s at column %d."
+	msgDeprecatedNotAChoice   = "Deprecated choice is not a valid choice: %s=%s."
+
+	// Check
+	msgCheckDuplicateArg     = "Duplicate argument in %s: %s is already used by %s."
+	msgCheckDuplicateCmd     = "Duplicate command in %s: %s is already used by %s."
+	msgCheckReservedCmd      = "Reserved command in %s: %s is built in."
+	msgCheckMultipleDefaults = "Multiple default commands in %s: %s and %s."
+	msgCheckRejectedCmd      = "Command never added in %s: %s collided with a command since removed."
+	msgCheckDemotedCmd       = "Default command demoted in %s: %s lost to a default since removed."
+	msgCheckRequiredDefault  = "Required argument with a default in %s: %s."
+	msgCheckBoolChoices      = "Bool argument with choices in %s: %s."
+	msgCheckNothingToExec    = "Command with nothing to execute: %s."
+	msgCheckHelpTemplate     = "Invalid help template in %s: %s"
+	msgCheckUndeclaredGroup  = "Undeclared group in %s: %s is in %s."
+	msgCheckExample          = "Example in %s doesn't resolve: %s: %s"
+
+	// Warnings
+	msgWarning               = "Warning: %s"
+	msgDeprecated            = "Deprecated %s: %s."
+	msgDeprecatedReplacement = "Use %s instead."
+
+	// Help
+	helpAll              = "all"
+	helpFlagLong         = "--help"
+	helpFlagShort        = "-h"
+	helpMarkerHidden     = "(hidden)"
+	helpMarkerDeprecated = "(deprecated)"
+	helpFieldsHint       = "Pick fields out with a template, as in %s='{{.%s}}', or a path, as in %s='{.%s}'."
+	helpFieldsUnknown    = "Any field of the records returned can be picked out with a template, as in %s='{{.field}}', or a path, as in %s='{.field}'."
+
+	// Deprecation kinds
+	deprecatedArg    = "argument"
+	deprecatedChoice = "choice"
+	deprecatedCmd    = "command"
+
+	// Suggestions
+	suggestDistanceDefault = 2
+	suggestMinLength       = 3
+
+	// Usage
+	usageGlobalArgs = "[global args]"
+	usageCommand    = "<command>"
+	usageIndent     = 4
+	usageMinWidth   = 20
+
+	// Terminal
+	terminalWidthDefault = 80
 
 	// Tables
-	tablePadDefault = uint8(4)
+	tablePadDefault   = uint8(4)
+	tableWrapMinWidth = 10
+	tableEllipsis     = "…"
+
+	// Output
+	outputArg         = "output"
+	outputArgDesc     = "How command output is formatted: one of %s, a template like '{{.name}}' or a path like '{.name}'."
+	outputColumnsArg  = "columns"
+	outputColumnsDesc = "Which columns to show, and in what order."
+	outputValueColumn = "value"
+	outputPlaceholder = "format"
+
+	outputTemplatePrefix = "template:"
+	outputPathPrefix     = "path:"
+
+	// Color
+	colorArg     = "color"
+	colorArgDesc = "When to use color: auto, always or never."
+
+	// Completion
+	completionName      = "completion"
+	completionDesc      = "Output a shell completion script."
+	completionShellDesc = "Output a %s completion script."
+	completeName        = "__complete"
+	completeDesc        = "Complete the words that follow, for completion scripts."
+
+	// Man pages
+	manSuffix       = ".1"
+	manAliases      = "Aliases"
+	manKind         = "Kind"
+	manDefault      = "Default"
+	manChoices      = "Choices"
+	manMultiple     = "Takes more than one value, separated by \"%s\"."
+	manRequired     = "Required."
+	manEnvDesc      = "Used for %s when it isn't given."
+	manNoColorDesc  = "Turns color off, unless color=always is given."
+	manPagesName    = "__man"
+	manPagesDesc    = "Write man pages for the app and its commands."
+	manPagesDirArg  = "dir"
+	manPagesDirDesc = "The directory to write them to."
 
 	// Tests
 	testAppName    string = "myApp"
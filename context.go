@@ -0,0 +1,62 @@
+package cligobrr
+
+import "io"
+
+// Context is handed to ExecWithContext and ExecWithOutput so a command
+// has everything it needs without reaching for globals: the parsed
+// args, and the writers and reader the App was configured with.
+type Context struct {
+	App     *App
+	Cmd     *Cmd
+	AppArgs Args
+	Args    Args
+	Out     io.Writer
+	Err     io.Writer
+	In      io.Reader
+}
+
+// Output writes records to Out, in the format the output arg asks
+// for, with the columns the columns arg asks for. Without those args
+// (see AppFields.Output), it's a table with the command's Columns.
+// Templates and paths pick out their own fields, so the command's
+// Columns don't apply to them. A table's headers are colored the way
+// help's are. See OutputOf for what records can be.
+func (self *Context) Output(records any) error {
+	output, err := OutputOf(records)
+	if err != nil {
+		return err
+	}
+
+	var styles map[Style]string
+	if self.App != nil {
+		styles = self.App.Styles
+	}
+
+	output.colors = paletteFor(self.Out, colorMode(&self.AppArgs), styles)
+	output.app = self.App
+
+	format := OutputTable
+	arg := self.AppArgs.Lookup(outputArg)
+	if arg != nil && len(arg.Stored()) > 0 {
+		format = arg.Stored()[0]
+	}
+
+	var columns []string
+	if self.Cmd != nil && !outputExpression(format) {
+		columns = self.Cmd.Columns
+	}
+
+	arg = self.AppArgs.Lookup(outputColumnsArg)
+	if arg != nil && len(arg.Stored()) > 0 {
+		columns = arg.Stored()
+	}
+
+	if len(columns) > 0 {
+		err = output.Select(columns)
+		if err != nil {
+			return err
+		}
+	}
+
+	return output.Write(self.Out, format)
+}
@@ -0,0 +1,42 @@
+package cligobrr
+
+import "fmt"
+import "io"
+import "strings"
+
+// Deprecation marks a command, arg, or choice as something that still
+// works but is on its way out. Replacement, when given, is what should
+// be used instead.
+type Deprecation struct {
+	Message     string
+	Replacement string
+}
+
+// Warning is raised when something deprecated is used during parsing.
+type Warning struct {
+	Deprecation
+	Kind string
+	Name string
+}
+
+func (self Warning) String() string {
+	parts := []string{fmt.Sprintf(msgDeprecated, self.Kind, self.Name)}
+
+	replacement := strings.TrimSpace(self.Replacement)
+	if len(replacement) > 0 {
+		parts = append(parts, fmt.Sprintf(msgDeprecatedReplacement, replacement))
+	}
+
+	message := strings.TrimSpace(self.Message)
+	if len(message) > 0 {
+		parts = append(parts, message)
+	}
+
+	return strings.Join(parts, " ")
+}
+
+func emitWarnings(writer io.Writer, colors palette, warnings []Warning) {
+	for _, warning := range warnings {
+		fmt.Fprintln(writer, colors.paint(StyleWarning, fmt.Sprintf(msgWarning, warning.String())))
+	}
+}
@@ -0,0 +1,153 @@
+package cligobrr
+
+import "bytes"
+import "fmt"
+import "testing"
+import "github.com/stretchr/testify/assert"
+
+func TestWarningString(t *testing.T) {
+	assert := assert.New(t)
+
+	warning := Warning{
+		Kind: deprecatedCmd,
+		Name: "rm",
+	}
+	assert.Equal("Deprecated command: rm.", warning.String())
+
+	warning.Replacement = "remove"
+	assert.Equal("Deprecated command: rm. Use remove instead.", warning.String())
+
+	warning.Message = "It goes away in 2.0."
+	assert.Equal("Deprecated command: rm. Use remove instead. It goes away in 2.0.", warning.String())
+}
+
+func TestAppParseDeprecatedCmd(t *testing.T) {
+	assert := assert.New(t)
+
+	app := AppNew(AppFields{Name: testAppName})
+
+	cmdFields := CmdFields{
+		Name:       "rm",
+		Exec:       testCmdExec,
+		Deprecated: &Deprecation{Replacement: "remove"},
+	}
+
+	cmd, err := CmdNew(cmdFields)
+	assert.Nil(err)
+
+	app.Cmds.Add(cmd)
+
+	cmdToExec, err := app.Parse([]string{testAppName, "rm"})
+	assert.Nil(err)
+	assert.Equal("rm", cmdToExec.Name)
+
+	warnings := app.Warnings()
+	assert.Equal(1, len(warnings))
+	assert.Equal(deprecatedCmd, warnings[0].Kind)
+	assert.Equal("rm", warnings[0].Name)
+	assert.Equal("remove", warnings[0].Replacement)
+
+	// Warnings don't carry over from one parse to the next.
+	_, err = app.Parse([]string{testAppName, "version"})
+	assert.Nil(err)
+	assert.Empty(app.Warnings())
+}
+
+func TestCmdParseDeprecatedArgAndChoice(t *testing.T) {
+	assert := assert.New(t)
+
+	cmd, err := CmdNew(CmdFields{Name: testCmdName, Exec: testCmdExec})
+	assert.Nil(err)
+
+	arg1Fields := ArgFields{
+		Name:       "zone",
+		Deprecated: &Deprecation{Replacement: "region"},
+	}
+
+	arg1, err := StringArgNew(arg1Fields)
+	assert.Nil(err)
+
+	cmd.Args.Add(arg1)
+
+	arg2Fields := ArgFields{
+		Name:    "region",
+		Choices: []string{"us-east", "eu-central", "us"},
+		DeprecatedChoices: map[string]Deprecation{
+			"us": {Replacement: "us-east"},
+		},
+	}
+
+	arg2, err := StringArgNew(arg2Fields)
+	assert.Nil(err)
+
+	cmd.Args.Add(arg2)
+
+	_, err = cmd.Parse([]string{"region=eu-central"})
+	assert.Nil(err)
+	assert.Empty(cmd.Warnings())
+
+	_, err = cmd.Parse([]string{"zone=a", "region=us"})
+	assert.Nil(err)
+
+	warnings := cmd.Warnings()
+	assert.Equal(2, len(warnings))
+	assert.Equal(deprecatedArg, warnings[0].Kind)
+	assert.Equal("zone", warnings[0].Name)
+	assert.Equal(deprecatedChoice, warnings[1].Kind)
+	assert.Equal("region=us", warnings[1].Name)
+
+	region, err := cmd.Args.AsString("region")
+	assert.Nil(err)
+	assert.Equal("us", region)
+}
+
+func TestParseWarningsGoToAppErr(t *testing.T) {
+	assert := assert.New(t)
+
+	var errOut bytes.Buffer
+	app := AppNew(AppFields{Name: testAppName, Err: &errOut})
+
+	cmd, err := CmdNew(CmdFields{Name: testCmdName, Exec: testCmdExec})
+	assert.Nil(err)
+
+	arg, err := StringArgNew(ArgFields{
+		Name:       "zone",
+		Deprecated: &Deprecation{Replacement: "region"},
+	})
+	assert.Nil(err)
+
+	cmd.Args.Add(arg)
+	app.Cmds.Add(cmd)
+
+	warning := fmt.Sprintf(msgWarning, Warning{Kind: deprecatedArg, Name: "zone", Deprecation: Deprecation{Replacement: "region"}}.String()) + "\n"
+
+	_, err = cmd.Parse([]string{"zone=a"})
+	assert.Nil(err)
+	assert.Equal(warning, errOut.String())
+
+	errOut.Reset()
+
+	err = cmd.Args.Parse([]string{"zone=a"})
+	assert.Nil(err)
+	assert.Equal(warning, errOut.String())
+}
+
+func TestArgNewDeprecatedChoiceNotAChoice(t *testing.T) {
+	assert := assert.New(t)
+
+	fields := ArgFields{
+		Name:    "region",
+		Choices: []string{"us-east", "eu-central"},
+		DeprecatedChoices: map[string]Deprecation{
+			" us ": {Replacement: "us-east"},
+		},
+	}
+
+	_, err := argNew(fields)
+	assert.NotNil(err)
+
+	fields.Choices = append(fields.Choices, "us")
+	arg, err := argNew(fields)
+	assert.Nil(err)
+	assert.Contains(arg.GetDeprecatedChoices(), "us")
+}
@@ -2,17 +2,23 @@ package cligobrr
 
 import "fmt"
 import "errors"
+import "strings"
 
 func errDefaultNotAValidChoice(name string) error {
 	msg := fmt.Sprintf(msgDefaultNotAValidChoice, name)
 	return errors.New(msg)
 }
 
-func errInvalidArgValue(name string, value string) error {
-	msg := fmt.Sprintf(msgInvalidArgValue, name, value)
+func errDeprecatedNotAChoice(name string, choice string) error {
+	msg := fmt.Sprintf(msgDeprecatedNotAChoice, name, choice)
 	return errors.New(msg)
 }
 
+func errInvalidArgValue(name string, value string, suggestions []string) error {
+	msg := fmt.Sprintf(msgInvalidArgValue, name, value)
+	return errors.New(withSuggestions(msg, suggestions))
+}
+
 func errMissingArgValue(name string) error {
 	msg := fmt.Sprintf(msgMissingArgValue, name)
 	return errors.New(msg)
@@ -32,14 +38,14 @@ func errNameRequired() error {
 	return errors.New(msgNameRequired)
 }
 
-func errUnexpectedArg(token string) error {
+func errUnexpectedArg(token string, suggestions []string) error {
 	msg := fmt.Sprintf(msgUnexpectedArg, token)
-	return errors.New(msg)
+	return errors.New(withSuggestions(msg, suggestions))
 }
 
-func errUnexpectedCmd(token string) error {
+func errUnexpectedCmd(token string, suggestions []string) error {
 	msg := fmt.Sprintf(msgUnexpectedCmd, token)
-	return errors.New(msg)
+	return errors.New(withSuggestions(msg, suggestions))
 }
 
 func errArgHasNoValues(name string) error {
@@ -55,3 +61,139 @@ func errTableRowIncorrectCols(cols uint8) error {
 	msg := fmt.Sprintf(msgTableRowIncorrectCols, cols)
 	return errors.New(msg)
 }
+
+func errArgNotFound(identifier string) error {
+	msg := fmt.Sprintf(msgArgNotFound, identifier)
+	return errors.New(msg)
+}
+
+func errCmdNotFound(identifier string) error {
+	msg := fmt.Sprintf(msgCmdNotFound, identifier)
+	return errors.New(msg)
+}
+
+func errArgInUse(identifier string) error {
+	msg := fmt.Sprintf(msgArgInUse, identifier)
+	return errors.New(msg)
+}
+
+func errCmdInUse(identifier string) error {
+	msg := fmt.Sprintf(msgCmdInUse, identifier)
+	return errors.New(msg)
+}
+
+func errUnknownColumn(name string, suggestions []string) error {
+	msg := fmt.Sprintf(msgUnknownColumn, name)
+	return errors.New(withSuggestions(msg, suggestions))
+}
+
+func errUnknownOutputFormat(format string, suggestions []string) error {
+	msg := fmt.Sprintf(msgUnknownOutputFormat, format)
+	return errors.New(withSuggestions(msg, suggestions))
+}
+
+func errUnknownShell(shell string, suggestions []string) error {
+	msg := fmt.Sprintf(msgUnknownShell, shell)
+	return errors.New(withSuggestions(msg, suggestions))
+}
+
+func errUnknownStyle(name string, suggestions []string) error {
+	msg := fmt.Sprintf(msgUnknownStyle, name)
+	return errors.New(withSuggestions(msg, suggestions))
+}
+
+func errUnsupportedOutput(kind string) error {
+	msg := fmt.Sprintf(msgUnsupportedOutput, kind)
+	return errors.New(msg)
+}
+
+func errOutputTemplate(err error, fields []string) error {
+	msg := fmt.Sprintf(msgOutputTemplate, err)
+	if len(fields) > 0 {
+		msg = fmt.Sprintf("%s. %s", msg, fmt.Sprintf(msgOutputTemplateFields, strings.Join(fields, ", ")))
+	}
+
+	return errors.New(msg)
+}
+
+// errOutputPath points out where in expr things went wrong.
+func errOutputPath(expr string, pos int, reason string) error {
+	msg := fmt.Sprintf(msgOutputPath, reason, pos+1)
+	caret := strings.Repeat(" ", displayWidth(expr[:min(pos, len(expr))]))
+
+	return errors.New(fmt.Sprintf("%s\n\n    %s\n    %s^", msg, expr, caret))
+}
+
+func errHelpTemplate(err error) error {
+	msg := fmt.Sprintf(msgHelpTemplate, err)
+	return errors.New(msg)
+}
+
+func errCheckDuplicateArg(path string, identifier string, existing string) error {
+	msg := fmt.Sprintf(msgCheckDuplicateArg, path, identifier, existing)
+	return errors.New(msg)
+}
+
+func errCheckDuplicateCmd(path string, identifier string, existing string) error {
+	msg := fmt.Sprintf(msgCheckDuplicateCmd, path, identifier, existing)
+	return errors.New(msg)
+}
+
+func errCheckReservedCmd(path string, identifier string) error {
+	msg := fmt.Sprintf(msgCheckReservedCmd, path, identifier)
+	return errors.New(msg)
+}
+
+func errCheckMultipleDefaults(path string, first string, second string) error {
+	msg := fmt.Sprintf(msgCheckMultipleDefaults, path, first, second)
+	return errors.New(msg)
+}
+
+func errCheckRejectedCmd(path string, name string) error {
+	msg := fmt.Sprintf(msgCheckRejectedCmd, path, name)
+	return errors.New(msg)
+}
+
+func errCheckDemotedCmd(path string, name string) error {
+	msg := fmt.Sprintf(msgCheckDemotedCmd, path, name)
+	return errors.New(msg)
+}
+
+func errCheckRequiredDefault(path string, name string) error {
+	msg := fmt.Sprintf(msgCheckRequiredDefault, path, name)
+	return errors.New(msg)
+}
+
+func errCheckBoolChoices(path string, name string) error {
+	msg := fmt.Sprintf(msgCheckBoolChoices, path, name)
+	return errors.New(msg)
+}
+
+func errCheckNothingToExec(path string) error {
+	msg := fmt.Sprintf(msgCheckNothingToExec, path)
+	return errors.New(msg)
+}
+
+func errCheckUndeclaredGroup(path string, name string, group string) error {
+	msg := fmt.Sprintf(msgCheckUndeclaredGroup, path, name, group)
+	return errors.New(msg)
+}
+
+func errCheckExample(path string, command string, err error) error {
+	msg := fmt.Sprintf(msgCheckExample, path, command, err)
+	return errors.New(msg)
+}
+
+func errCheckHelpTemplate(path string, err error) error {
+	msg := fmt.Sprintf(msgCheckHelpTemplate, path, err)
+	return errors.New(msg)
+}
+
+func withSuggestions(msg string, suggestions []string) string {
+	if len(suggestions) == 0 {
+		return msg
+	}
+
+	didYouMean := fmt.Sprintf(msgDidYouMean, strings.Join(suggestions, ", "))
+	return fmt.Sprintf("%s %s", msg, didYouMean)
+}
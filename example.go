@@ -0,0 +1,48 @@
+package cligobrr
+
+import "errors"
+import "strings"
+
+// Example is a worked example shown in a command's help. Command is
+// the whole command line, with or without the app name in front, and
+// is split on whitespace with no shell quoting.
+type Example struct {
+	Command     string
+	Description string
+}
+
+// CheckExamples resolves every example in the tree as though it had
+// been typed on the command line, so that examples which have gone
+// stale can fail a test rather than mislead someone reading help.
+// Every example that doesn't resolve is reported, not just the first.
+func (self *App) CheckExamples() error {
+	var errs []error
+
+	self.Cmds.Walk(func(path []*Cmd) error {
+		cmd := path[len(path)-1]
+
+		for _, example := range cmd.Examples {
+			command := exampleCommand(self, example.Command)
+
+			_, err := self.Resolve(strings.Fields(command))
+			if err != nil {
+				errs = append(errs, errCheckExample(cmd.Path(), command, err))
+			}
+		}
+
+		return nil
+	})
+
+	return errors.Join(errs...)
+}
+
+// exampleCommand puts the app name in front of command,
+// unless it's already there.
+func exampleCommand(app *App, command string) string {
+	fields := strings.Fields(command)
+	if app == nil || (len(fields) > 0 && fields[0] == app.Name) {
+		return strings.Join(fields, " ")
+	}
+
+	return strings.Join(append([]string{app.Name}, fields...), " ")
+}
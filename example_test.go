@@ -0,0 +1,47 @@
+package cligobrr
+
+import "testing"
+import "github.com/stretchr/testify/assert"
+
+func TestExampleCommand(t *testing.T) {
+	assert := assert.New(t)
+
+	app := AppNew(AppFields{Name: testAppName})
+
+	assert.Equal("myApp deploy env=staging", exampleCommand(app, "deploy env=staging"))
+	assert.Equal("myApp deploy env=staging", exampleCommand(app, "  myApp deploy   env=staging "))
+	assert.Equal("deploy", exampleCommand(nil, "deploy"))
+}
+
+func TestCheckExamples(t *testing.T) {
+	assert := assert.New(t)
+
+	app := AppNew(AppFields{Name: testAppName})
+
+	deploy, _ := CmdNew(CmdFields{Name: "deploy"})
+	status, _ := CmdNew(CmdFields{
+		Name: "status",
+		Exec: testCmdExec,
+		Examples: []Example{
+			{Command: "deploy status env=staging"},
+			{Command: "myApp deploy status"},
+		},
+	})
+
+	env, _ := StringArgNew(ArgFields{Name: "env", Choices: []string{"staging", "production"}})
+	status.Args.Add(env)
+
+	deploy.Cmds.Add(status)
+	app.Cmds.Add(deploy)
+
+	assert.Nil(app.CheckExamples())
+
+	status.Examples = append(status.Examples,
+		Example{Command: "deploy stats"},
+		Example{Command: "deploy status env=qa"},
+	)
+
+	err := app.CheckExamples()
+	assert.ErrorContains(err, "Example in myApp deploy status doesn't resolve: myApp deploy stats:")
+	assert.ErrorContains(err, "Example in myApp deploy status doesn't resolve: myApp deploy status env=qa:")
+}
@@ -1,175 +1,721 @@
 package cligobrr
 
+import _ "embed"
 import "fmt"
+import "io"
+import "reflect"
+import "slices"
 import "strings"
 import "strconv"
+import "text/template"
 
-func appHelp(app *App, input []string) error {
-	return help(
-		app.Name,
-		app.Description,
-		app.Cmds,
-		app.Args,
-		input,
-	)
-}
-
-func cmdHelp(cmd *Cmd, input []string) error {
-	return help(
-		cmd.Name,
-		cmd.Description,
-		cmd.Cmds,
-		cmd.Args,
-		input,
-	)
-}
-
-func help(
-	name string,
-	description string,
-	commands Cmds,
-	arguments Args,
-	input []string,
-) error {
-	helpHeader(name, description)
-
-	if len(input) > 0 {
-		token := input[0]
-		arg := arguments.get(token)
-		if arg == nil {
-			return errUnexpectedArg(token)
-		}
-
-		helpUsage(name, []IArg{*arg})
-		helpSingleArg(*arg)
-	} else {
-		args := arguments.args
-		if len(args) > 0 {
-			helpUsage(name, args)
-			helpAllArgs(name, args)
-		}
+func appHelp(out io.Writer, app *App, input []string, colors palette) error {
+	return help(out, app, nil, input, colors)
+}
+
+func cmdHelp(out io.Writer, cmd *Cmd, input []string, colors palette) error {
+	return help(out, cmd.App(), cmd, input, colors)
+}
+
+// HelpModel is everything help knows about whatever it is describing,
+// and what the help templates are executed with.
+type HelpModel struct {
+	// App is nil for a command that doesn't belong to one, and
+	// Cmd is nil when the help is for the app itself.
+	App *App
+	Cmd *Cmd
+
+	Name        string
+	Path        string
+	Description string
+	Usage       []string
+
+	// Topic is set when help was asked for a single arg, in which
+	// case Args, Sections and Cmds are left empty.
+	Topic IArg
+
+	// All is set for `help all`, which lists hidden and deprecated
+	// args and commands along with everything else.
+	All bool
+
+	Args     []IArg
+	Sections []HelpSection
+	Cmds     []*Cmd
+	Groups   []HelpGroup
+
+	// Examples have the app name in front of every Command.
+	Examples []Example
+
+	// Output is set when the command outputs records. Fields are
+	// those records' fields, which can be chosen as columns or used
+	// in templates and paths. They're empty when neither Records nor
+	// Columns says what the records will be.
+	Output bool
+	Fields []HelpField
+}
+
+// HelpField is a field of the records a command outputs. Type is
+// empty when all that is known is the field's name.
+type HelpField struct {
+	Name string
+	Type string
+}
+
+// HelpSection is a group of args sharing the same Section. Args
+// without one are in a section with an empty Title.
+type HelpSection struct {
+	Title string
+	Args  []IArg
+}
+
+// help displays help for cmd or, when cmd is nil, for app. Either
+// may be missing the other: a command doesn't have to belong to an
+// app to have help.
+func help(out io.Writer, app *App, cmd *Cmd, input []string, colors palette) error {
+	width := terminalWidth(out)
 
-		helpCmds(commands.cmds)
+	model, err := helpModel(app, cmd, input, width)
+	if err != nil {
+		return err
 	}
 
-	return nil
+	tmpl, err := helpTemplate(app, cmd, colors, width)
+	if err != nil {
+		return err
+	}
+
+	return tmpl.Execute(out, model)
 }
 
-func helpHeader(name, description string) {
-	tableFields := TableFields{
-		Cols: 2,
+func helpModel(app *App, cmd *Cmd, input []string, width int) (HelpModel, error) {
+	model := HelpModel{
+		App: app,
+		Cmd: cmd,
+	}
+
+	var commands Cmds
+	var arguments Args
+
+	if cmd != nil {
+		model.Name = cmd.Name
+		model.Path = cmd.Path()
+		model.Description = cmd.Description
+		commands = cmd.Cmds
+		arguments = cmd.Args
+	} else {
+		model.Name = app.Name
+		model.Path = app.Name
+		model.Description = app.Description
+		commands = app.Cmds
+		arguments = app.Args
+	}
+
+	topic, all, err := helpTopic(arguments, input)
+	if err != nil {
+		return model, err
+	}
+
+	model.All = all
+
+	if topic != nil {
+		model.Topic = *topic
+		model.Usage = usage(app, cmd, []IArg{*topic}, false, width)
+
+		return model, nil
 	}
 
-	table, _ := tableNew(tableFields)
-	table.Add([]string{"Name:", name})
-	if len(description) > 0 {
-		table.Add([]string{"Description:", description})
+	if cmd != nil {
+		model.Output = cmd.ExecWithOutput != nil
+		model.Fields = helpFields(cmd)
+
+		for _, example := range cmd.Examples {
+			model.Examples = append(model.Examples, Example{
+				Command:     exampleCommand(app, example.Command),
+				Description: strings.TrimSpace(example.Description),
+			})
+		}
 	}
 
-	fmt.Println(table.ToString())
-	fmt.Println("")
+	model.Args = visibleArgs(arguments.args, all)
+	model.Sections = helpArgSections(model.Args)
+	model.Cmds = visibleCmds(commands.cmds, all)
+	model.Groups = helpCmdGroups(model.Cmds, commands.groups(), commands.sortCmds())
+	model.Usage = usage(app, cmd, model.Args, true, width)
+
+	return model, nil
 }
 
-func helpUsage(name string, args []IArg) {
-	output := []string{"Usage:", ""}
-	cmdLine := []string{name}
+//go:embed help.tmpl
+var helpTemplateDefault string
 
-	for _, arg := range args {
-		fragment := fmt.Sprintf("%s=%s", arg.GetName(), arg.GetKind())
+// helpTemplate puts together the templates used to render help: the
+// defaults, then the app's, then the command's, each able to redefine
+// anything that came before.
+func helpTemplate(app *App, cmd *Cmd, colors palette, width int) (*template.Template, error) {
+	tmpl := template.Must(template.New("help").Funcs(helpFuncs(app, colors, width)).Parse(helpTemplateDefault))
+
+	var overrides []string
+	if app != nil {
+		overrides = append(overrides, app.HelpTemplate)
+	}
+
+	if cmd != nil {
+		overrides = append(overrides, cmd.HelpTemplate)
+	}
 
-		if !arg.GetRequired() {
-			fragment = fmt.Sprintf("[%s]", fragment)
+	for _, override := range overrides {
+		if len(strings.TrimSpace(override)) == 0 {
+			continue
 		}
 
-		cmdLine = append(cmdLine, fragment)
+		_, err := tmpl.Parse(override)
+		if err != nil {
+			return nil, errHelpTemplate(err)
+		}
+	}
+
+	return tmpl, nil
+}
+
+// helpFuncs are available to every help template. The tables are
+// rendered in Go, because lining up columns in a template isn't
+// anyone's idea of fun. They, and the style func, color things
+// when colors is on.
+func helpFuncs(app *App, colors palette, width int) template.FuncMap {
+	return template.FuncMap{
+		"join":     strings.Join,
+		"label":    label,
+		"argLabel": argLabel,
+		"style": func(name string, text string) (string, error) {
+			return colors.style(app, name, text)
+		},
+		"header": func(model HelpModel) string {
+			return helpHeader(model, colors, width)
+		},
+		"argDetail": func(arg IArg) string {
+			return helpSingleArg(arg, colors, width)
+		},
+		"argTable": func(all []IArg, args []IArg) string {
+			return helpArgTable(all, args, colors, width)
+		},
+		"cmdTable": func(cmds []*Cmd) string {
+			return helpCmdTable(cmds, colors, width)
+		},
+		"hasDefault": hasDefaultCmd,
+		"fieldTable": func(fields []HelpField) string {
+			return helpFieldTable(fields, colors, width)
+		},
+		"fieldHint": helpFieldHint,
+	}
+}
+
+// helpTopic works out what the help input is asking about: a single
+// arg, or everything. `help all` reveals the hidden and deprecated
+// things that are normally left out, unless there is an actual arg
+// named 'all'.
+func helpTopic(arguments Args, input []string) (*IArg, bool, error) {
+	all := false
+	if len(input) > 0 && input[0] == helpAll && arguments.get(helpAll) == nil {
+		all = true
+		input = input[1:]
+	}
+
+	if len(input) == 0 {
+		return nil, all, nil
+	}
+
+	token := input[0]
+	arg := arguments.get(token)
+	if arg == nil {
+		return nil, all, errUnexpectedArg(token, arguments.owner().suggest(token, arguments.identifiers()))
+	}
+
+	return arg, all, nil
+}
+
+func helpHeader(model HelpModel, colors palette, width int) string {
+	tableFields := TableFields{
+		Cols:  2,
+		Width: width,
+		Paint: colors.labels,
+	}
+
+	table, _ := TableNew(tableFields)
+	table.Add([]string{"Name:", model.Name})
+	if len(model.Description) > 0 {
+		table.Add([]string{"Description:", model.Description})
 	}
 
-	output = append(output, strings.Join(cmdLine, " "))
-	fmt.Println(strings.Join(output, "\n"))
-	fmt.Println("")
+	return table.ToString()
 }
 
-func helpSingleArg(arg IArg) {
+func helpSingleArg(arg IArg, colors palette, width int) string {
 	tableFields := TableFields{
-		Cols: 2,
+		Cols:  2,
+		Width: width,
+		Paint: colors.labels,
 	}
 
-	table, _ := tableNew(tableFields)
+	table, _ := TableNew(tableFields)
 	table.Add([]string{"Name:", arg.GetName()})
-	table.Add([]string{"Alias:", arg.GetAlias()})
+	table.Add([]string{"Aliases:", strings.Join(arg.GetAliases(), ", ")})
 	table.Add([]string{"Description:", arg.GetDescription()})
 	table.Add([]string{"Kind:", arg.GetKind()})
 	table.Add([]string{"Multiple:", strconv.FormatBool(arg.GetMultiple())})
 	table.Add([]string{"Required:", strconv.FormatBool(arg.GetRequired())})
 	table.Add([]string{"Default:", arg.GetDefault()})
-	table.Add([]string{"Choices:", strings.Join(arg.GetChoices(), arg.GetSeparator())})
-	fmt.Println(table.ToString())
-	fmt.Println("")
+	if len(arg.GetEnv()) > 0 {
+		table.Add([]string{"Env:", arg.GetEnv()})
+	}
+
+	// Deprecated choices still work, so they're listed, but separately
+	// so nobody picks one up by accident.
+	var choices []string
+	var deprecatedChoices []string
+	for _, choice := range arg.GetChoices() {
+		if _, ok := arg.GetDeprecatedChoices()[choice]; ok {
+			deprecatedChoices = append(deprecatedChoices, choice)
+		} else {
+			choices = append(choices, choice)
+		}
+	}
+
+	table.Add([]string{"Choices:", strings.Join(choices, arg.GetSeparator())})
+	if len(deprecatedChoices) > 0 {
+		table.Add([]string{"Deprecated choices:", strings.Join(deprecatedChoices, arg.GetSeparator())})
+	}
+
+	deprecation := arg.GetDeprecated()
+	if deprecation != nil {
+		warning := Warning{
+			Deprecation: *deprecation,
+			Kind:        deprecatedArg,
+			Name:        arg.GetName(),
+		}
+		table.Add([]string{"Deprecated:", warning.String()})
+	}
+
+	return table.ToString()
+}
+
+// HelpGroup is a group of commands sharing the same Group. Commands
+// without one, including the built-in ones, are in a group with an
+// empty Title, which always comes last.
+type HelpGroup struct {
+	Title string
+	Cmds  []*Cmd
+}
+
+// helpAllArgs lists args the way the default template does.
+func helpAllArgs(out io.Writer, path string, args []IArg) error {
+	model := HelpModel{
+		Path:     path,
+		Args:     args,
+		Sections: helpArgSections(args),
+	}
+
+	tmpl, _ := helpTemplate(nil, nil, palette{}, terminalWidth(out))
+
+	return tmpl.ExecuteTemplate(out, "args", model)
 }
 
-func helpAllArgs(name string, args []IArg) {
+// helpArgTable lists args, one per row. The columns are worked out
+// from all of them, not just the ones listed, so that every section
+// has the same columns.
+func helpArgTable(all []IArg, args []IArg, colors palette, width int) string {
+	columns := helpArgColumns(all)
 
-	output := []string{
-		"Arguments:",
-		"",
+	var headers []string
+	for _, column := range columns {
+		headers = append(headers, column.header)
 	}
 
 	tableFields := TableFields{
-		Cols: 2,
+		Cols:    uint8(len(columns)),
+		Headers: headers,
+		Width:   width,
+		Paint: func(row int, col int, cell string) string {
+			switch {
+			case row < 0:
+				return colors.paint(StyleHeading, cell)
+			case columns[col].header == "Name" && args[row].GetRequired():
+				return colors.paint(StyleRequired, cell)
+			case columns[col].header == "Required":
+				return colors.paint(StyleRequired, cell)
+			case columns[col].header == "Default":
+				return colors.paint(StyleDefault, cell)
+			}
+
+			return cell
+		},
 	}
 
-	table, _ := tableNew(tableFields)
-	table.Add([]string{"Name", "Description"})
-	table.Add([]string{"----", "-----------"})
+	table, _ := TableNew(tableFields)
 
 	for _, arg := range args {
-		table.Add([]string{arg.GetName(), arg.GetDescription()})
+		var row []string
+		for _, column := range columns {
+			row = append(row, column.value(arg))
+		}
+
+		table.Add(row)
+	}
+
+	return table.ToString()
+}
+
+type helpArgColumn struct {
+	header string
+	value  func(IArg) string
+}
+
+// helpArgColumns decides which columns are worth showing. Name and
+// Description always are; the rest only when at least one arg has
+// something to put in them.
+func helpArgColumns(args []IArg) []helpArgColumn {
+	all := []helpArgColumn{
+		{"Name", argLabel},
+		{"Aliases", func(arg IArg) string {
+			return strings.Join(arg.GetAliases(), ", ")
+		}},
+		{"Kind", func(arg IArg) string {
+			return arg.GetKind()
+		}},
+		{"Required", func(arg IArg) string {
+			if arg.GetRequired() {
+				return "yes"
+			}
+			return ""
+		}},
+		{"Default", func(arg IArg) string {
+			return arg.GetDefault()
+		}},
+		{"Choices", func(arg IArg) string {
+			return strings.Join(currentChoices(arg), arg.GetSeparator())
+		}},
+		{"Env", func(arg IArg) string {
+			return arg.GetEnv()
+		}},
+		{"Description", func(arg IArg) string {
+			return arg.GetDescription()
+		}},
+	}
+
+	var columns []helpArgColumn
+
+	for _, column := range all {
+		keep := column.header == "Name" || column.header == "Description"
+
+		for _, arg := range args {
+			if keep {
+				break
+			}
+
+			keep = len(column.value(arg)) > 0
+		}
+
+		if keep {
+			columns = append(columns, column)
+		}
 	}
 
-	output = append(output, table.ToString())
-	output = append(output, "")
-	output = append(output, fmt.Sprintf("`%s help arg` for more information.", name))
-	fmt.Println(strings.Join(output, "\n"))
-	fmt.Println("")
+	return columns
 }
 
-func helpCmds(cmds []Cmd) {
-	output := []string{
-		"Commands:",
-		"",
+// helpArgSections groups args by their Section, in the order each
+// section first appears. Args without one come first, untitled.
+func helpArgSections(args []IArg) []HelpSection {
+	sections := []HelpSection{{}}
+
+	for _, arg := range args {
+		i := slices.IndexFunc(sections, func(section HelpSection) bool {
+			return section.Title == arg.GetSection()
+		})
+
+		if i < 0 {
+			sections = append(sections, HelpSection{Title: arg.GetSection()})
+			i = len(sections) - 1
+		}
+
+		sections[i].Args = append(sections[i].Args, arg)
 	}
 
-	tableFields := TableFields{
-		Cols: 3,
+	if len(sections[0].Args) == 0 {
+		sections = sections[1:]
 	}
 
-	table, _ := tableNew(tableFields)
-	table.Add([]string{"Name", "Alias", "Description"})
-	table.Add([]string{"----", "-----", "-----------"})
+	return sections
+}
+
+// currentChoices leaves out deprecated choices, which still work
+// but shouldn't be suggested to anyone.
+func currentChoices(arg IArg) []string {
+	var choices []string
 
-	hasDefault := false
+	for _, choice := range arg.GetChoices() {
+		if _, ok := arg.GetDeprecatedChoices()[choice]; !ok {
+			choices = append(choices, choice)
+		}
+	}
+
+	return choices
+}
+
+func helpCmdTable(cmds []*Cmd, colors palette, width int) string {
+	tableFields := TableFields{
+		Cols:    3,
+		Headers: []string{"Name", "Aliases", "Description"},
+		Width:   width,
+		Paint: func(row int, col int, cell string) string {
+			switch {
+			case row < 0:
+				return colors.paint(StyleHeading, cell)
+			case col == 0:
+				return colors.paint(StyleCommand, cell)
+			}
+
+			return cell
+		},
+	}
+
+	table, _ := TableNew(tableFields)
 
 	for _, cmd := range cmds {
 		name := cmd.Name
 
 		if cmd.Default {
 			name = fmt.Sprintf("%s*", name)
-			hasDefault = true
 		}
 
+		name = label(name, cmd.Hidden, cmd.Deprecated != nil)
+
 		table.Add([]string{
 			name,
-			cmd.Alias,
+			strings.Join(cmd.GetAliases(), ", "),
 			cmd.Description,
 		})
 	}
 
-	output = append(output, table.ToString())
+	return table.ToString()
+}
+
+// helpCmdGroups groups cmds by their Group, declared groups first,
+// then any others in the order each first appears, then everything
+// without a group.
+func helpCmdGroups(cmds []*Cmd, order []string, sortCmds CmdSort) []HelpGroup {
+	var groups []HelpGroup
+	for _, title := range order {
+		groups = append(groups, HelpGroup{Title: strings.TrimSpace(title)})
+	}
+
+	other := HelpGroup{}
+
+	for _, cmd := range cmds {
+		title := strings.TrimSpace(cmd.Group)
+		if len(title) == 0 {
+			other.Cmds = append(other.Cmds, cmd)
+			continue
+		}
+
+		i := slices.IndexFunc(groups, func(group HelpGroup) bool {
+			return group.Title == title
+		})
+
+		if i < 0 {
+			groups = append(groups, HelpGroup{Title: title})
+			i = len(groups) - 1
+		}
+
+		groups[i].Cmds = append(groups[i].Cmds, cmd)
+	}
+
+	groups = slices.DeleteFunc(groups, func(group HelpGroup) bool {
+		return len(group.Cmds) == 0
+	})
+
+	if len(other.Cmds) > 0 || len(groups) == 0 {
+		groups = append(groups, other)
+	}
+
+	if sortCmds == CmdSortName {
+		for _, group := range groups {
+			slices.SortStableFunc(group.Cmds, func(a, b *Cmd) int {
+				return strings.Compare(a.Name, b.Name)
+			})
+		}
+	}
+
+	return groups
+}
+
+func hasDefaultCmd(cmds []*Cmd) bool {
+	return slices.ContainsFunc(cmds, func(cmd *Cmd) bool {
+		return cmd.Default
+	})
+}
+
+// Hidden and deprecated args and commands still work, but we
+// don't want to advertise them in listings unless asked to.
+func visibleArgs(args []IArg, all bool) []IArg {
+	var visible []IArg
+
+	for _, arg := range args {
+		if all || (!arg.GetHidden() && arg.GetDeprecated() == nil) {
+			visible = append(visible, arg)
+		}
+	}
+
+	return visible
+}
+
+func visibleCmds(cmds []*Cmd, all bool) []*Cmd {
+	var visible []*Cmd
+
+	for _, cmd := range cmds {
+		if all || (!cmd.Hidden && cmd.Deprecated == nil) {
+			visible = append(visible, cmd)
+		}
+	}
+
+	return visible
+}
+
+// When hidden or deprecated things are listed, they need to
+// stand out from everything else.
+func argLabel(arg IArg) string {
+	return label(arg.GetName(), arg.GetHidden(), arg.GetDeprecated() != nil)
+}
+
+func label(name string, hidden bool, deprecated bool) string {
+	labelled := []string{name}
+
+	if hidden {
+		labelled = append(labelled, helpMarkerHidden)
+	}
+
+	if deprecated {
+		labelled = append(labelled, helpMarkerDeprecated)
+	}
+
+	return strings.Join(labelled, " ")
+}
+
+// helpFields works out the fields of the records cmd outputs, from
+// the type of its Records or, failing that, its Columns.
+func helpFields(cmd *Cmd) []HelpField {
+	if cmd.ExecWithOutput == nil {
+		return nil
+	}
+
+	if cmd.Records != nil {
+		return helpFieldsOf(reflect.TypeOf(cmd.Records))
+	}
+
+	var fields []HelpField
+	for _, column := range cmd.Columns {
+		fields = append(fields, HelpField{Name: column})
+	}
+
+	return fields
+}
+
+// helpFieldsOf names the fields of a record of type t the same way
+// OutputOf does, but from the type alone. A map's keys aren't known
+// until there are values, so there's nothing to list for one.
+func helpFieldsOf(t reflect.Type) []HelpField {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+	}
+
+	switch {
+	case t.Kind() == reflect.Map && t.Key().Kind() == reflect.String:
+		return nil
+	case t.Kind() == reflect.Interface:
+		return nil
+	case t.Kind() != reflect.Struct || helpFieldScalar(t):
+		return []HelpField{{Name: outputValueColumn, Type: t.String()}}
+	}
+
+	var fields []HelpField
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			fields = append(fields, helpFieldsOf(field.Type)...)
+			continue
+		}
+
+		name := outputFieldName(field)
+		if name == "-" {
+			continue
+		}
+
+		fields = append(fields, HelpField{Name: name, Type: field.Type.String()})
+	}
+
+	return fields
+}
+
+// helpFieldScalar is outputScalar for a type rather than a value.
+func helpFieldScalar(t reflect.Type) bool {
+	stringer := reflect.TypeFor[fmt.Stringer]()
+	err := reflect.TypeFor[error]()
+
+	return t.Implements(stringer) || t.Implements(err) ||
+		reflect.PointerTo(t).Implements(stringer) || reflect.PointerTo(t).Implements(err)
+}
+
+// helpFieldTable lists fields, leaving out the Type
+// column when none of their types are known.
+func helpFieldTable(fields []HelpField, colors palette, width int) string {
+	typed := slices.ContainsFunc(fields, func(field HelpField) bool {
+		return len(field.Type) > 0
+	})
+
+	tableFields := TableFields{
+		Cols:    1,
+		Headers: []string{"Name"},
+		Width:   width,
+		Paint:   colors.headings,
+	}
+
+	if typed {
+		tableFields.Cols = 2
+		tableFields.Headers = append(tableFields.Headers, "Type")
+	}
+
+	table, _ := TableNew(tableFields)
+
+	for _, field := range fields {
+		row := []string{field.Name}
+		if typed {
+			row = append(row, field.Type)
+		}
+
+		table.Add(row)
+	}
+
+	return table.ToString()
+}
 
-	if hasDefault {
-		output = append(output, "")
-		output = append(output, "* indicates default command")
+// helpFieldHint shows how to pick out the first field, or any field
+// when there's none to go by.
+func helpFieldHint(fields []HelpField) string {
+	if len(fields) == 0 {
+		return fmt.Sprintf(helpFieldsUnknown, outputArg, outputArg)
 	}
 
-	fmt.Println(strings.Join(output, "\n"))
+	name := fields[0].Name
+	return fmt.Sprintf(helpFieldsHint, outputArg, name, outputArg, name)
 }
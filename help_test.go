@@ -0,0 +1,412 @@
+package cligobrr
+
+import "bytes"
+import "reflect"
+import "strings"
+import "testing"
+import "time"
+import "github.com/stretchr/testify/assert"
+
+func TestVisibleArgs(t *testing.T) {
+	assert := assert.New(t)
+
+	arg1, _ := StringArgNew(ArgFields{Name: "zone", Deprecated: &Deprecation{}})
+	arg2, _ := StringArgNew(ArgFields{Name: "region"})
+	arg3, _ := StringArgNew(ArgFields{Name: "debug", Hidden: true})
+	args := []IArg{arg1, arg2, arg3}
+
+	assert.Equal([]IArg{arg2}, visibleArgs(args, false))
+	assert.Equal(args, visibleArgs(args, true))
+}
+
+func TestVisibleCmds(t *testing.T) {
+	assert := assert.New(t)
+
+	cmd1, _ := CmdNew(CmdFields{Name: "rm", Deprecated: &Deprecation{}})
+	cmd2, _ := CmdNew(CmdFields{Name: "remove"})
+	cmd3, _ := CmdNew(CmdFields{Name: "__dump-state", Hidden: true})
+	cmds := []*Cmd{cmd1, cmd2, cmd3}
+
+	visible := visibleCmds(cmds, false)
+	assert.Equal(1, len(visible))
+	assert.Equal("remove", visible[0].Name)
+
+	assert.Equal(3, len(visibleCmds(cmds, true)))
+}
+
+func TestLabel(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("name", label("name", false, false))
+	assert.Equal("name (hidden)", label("name", true, false))
+	assert.Equal("name (deprecated)", label("name", false, true))
+	assert.Equal("name (hidden) (deprecated)", label("name", true, true))
+}
+
+func TestHiddenCmdStillParses(t *testing.T) {
+	assert := assert.New(t)
+
+	app := AppNew(AppFields{Name: testAppName})
+
+	cmd, err := CmdNew(CmdFields{Name: "__dump-state", Hidden: true, Exec: testCmdExec})
+	assert.Nil(err)
+
+	arg, err := BoolArgNew(ArgFields{Name: "verbose", Hidden: true})
+	assert.Nil(err)
+
+	cmd.Args.Add(arg)
+	app.Cmds.Add(cmd)
+
+	cmdToExec, err := app.Parse([]string{testAppName, "__dump-state", "verbose=true"})
+	assert.Nil(err)
+	assert.Equal("__dump-state", cmdToExec.Name)
+	verbose, err := cmdToExec.Args.AsBool("verbose")
+	assert.Nil(err)
+	assert.True(verbose)
+
+	// `help all` is a listing, not a request for an arg named 'all'.
+	_, err = app.Parse([]string{testAppName, "help", "all"})
+	assert.Nil(err)
+}
+
+func TestHelpArgColumns(t *testing.T) {
+	assert := assert.New(t)
+
+	headers := func(args []IArg) []string {
+		var headers []string
+		for _, column := range helpArgColumns(args) {
+			headers = append(headers, column.header)
+		}
+		return headers
+	}
+
+	arg1, _ := StringArgNew(ArgFields{Name: "host"})
+	assert.Equal([]string{"Name", "Kind", "Description"}, headers([]IArg{arg1}))
+
+	arg2, _ := IntArgNew(ArgFields{
+		Name:     "port",
+		Alias:    "p",
+		Required: true,
+		Env:      "MY_PORT",
+	})
+	arg3, _ := StringArgNew(ArgFields{
+		Name:    "format",
+		Default: "table",
+		Choices: []string{"table", "json"},
+	})
+	assert.Equal(
+		[]string{"Name", "Aliases", "Kind", "Required", "Default", "Choices", "Env", "Description"},
+		headers([]IArg{arg1, arg2, arg3}),
+	)
+}
+
+func TestHelpArgSections(t *testing.T) {
+	assert := assert.New(t)
+
+	host, _ := StringArgNew(ArgFields{Name: "host", Section: "Connection"})
+	format, _ := StringArgNew(ArgFields{Name: "format", Section: "Output"})
+	port, _ := IntArgNew(ArgFields{Name: "port", Section: "Connection"})
+	verbose, _ := BoolArgNew(ArgFields{Name: "verbose"})
+
+	sections := helpArgSections([]IArg{host, format, port, verbose})
+	assert.Equal(3, len(sections))
+	assert.Equal("", sections[0].Title)
+	assert.Equal([]IArg{verbose}, sections[0].Args)
+	assert.Equal("Connection", sections[1].Title)
+	assert.Equal([]IArg{host, port}, sections[1].Args)
+	assert.Equal("Output", sections[2].Title)
+	assert.Equal([]IArg{format}, sections[2].Args)
+
+	sections = helpArgSections([]IArg{host})
+	assert.Equal(1, len(sections))
+	assert.Equal("Connection", sections[0].Title)
+}
+
+func TestHelpAllArgs(t *testing.T) {
+	assert := assert.New(t)
+
+	var out bytes.Buffer
+
+	host, _ := StringArgNew(ArgFields{Name: "host", Section: "Connection", Env: "MY_HOST", Description: "Host to use."})
+	format, _ := StringArgNew(ArgFields{Name: "format", Default: "table", Choices: []string{"table", "json"}})
+
+	helpAllArgs(&out, testAppName, []IArg{host, format})
+
+	expected := strings.Join([]string{
+		"Arguments:",
+		"",
+		"Name      Kind      Default    Choices       Env    Description",
+		"----      ----      -------    -------       ---    -----------",
+		"format    string    table      table,json                      ",
+		"",
+		"Connection:",
+		"",
+		"Name    Kind      Default    Choices    Env        Description ",
+		"----    ----      -------    -------    ---        ----------- ",
+		"host    string                          MY_HOST    Host to use.",
+		"",
+		"`myApp help arg` for more information.",
+		"",
+		"",
+	}, "\n")
+
+	assert.Equal(expected, out.String())
+}
+
+func TestHelpTemplateOverride(t *testing.T) {
+	assert := assert.New(t)
+
+	var out bytes.Buffer
+
+	app := AppNew(AppFields{
+		Name:         testAppName,
+		Out:          &out,
+		HelpTemplate: `{{define "header"}}{{.Name}}!{{"\n\n"}}{{end}}`,
+	})
+
+	cmd, _ := CmdNew(CmdFields{
+		Name:         testCmdName,
+		Exec:         testCmdExec,
+		HelpTemplate: `{{.Path}} has {{len .Args}} args.`,
+	})
+	app.Cmds.Add(cmd)
+
+	// Only the header is redefined for the app.
+	assert.Nil(app.Run([]string{testAppName, "help"}))
+	assert.True(strings.HasPrefix(out.String(), "myApp!\n\nUsage:\n\n"))
+	assert.Contains(out.String(), "Commands:")
+
+	// The command replaces the whole thing.
+	out.Reset()
+	assert.Nil(app.Run([]string{testAppName, testCmdName, "help"}))
+	assert.Equal("myApp myCmd has 0 args.", out.String())
+}
+
+func TestHelpTemplateInvalid(t *testing.T) {
+	assert := assert.New(t)
+
+	var out bytes.Buffer
+
+	app := AppNew(AppFields{Name: testAppName, Out: &out, HelpTemplate: "{{.Name"})
+
+	err := app.Run([]string{testAppName, "help"})
+	assert.ErrorContains(err, "Invalid help template")
+	assert.Empty(out.String())
+}
+
+func TestHelpModel(t *testing.T) {
+	assert := assert.New(t)
+
+	app := AppNew(AppFields{Name: testAppName})
+	cmd, _ := CmdNew(CmdFields{Name: testCmdName, Description: "Does things.", Exec: testCmdExec})
+	arg, _ := StringArgNew(ArgFields{Name: testArgName, Section: "Mine"})
+	hidden, _ := StringArgNew(ArgFields{Name: "secret", Hidden: true})
+	cmd.Args.Add(arg)
+	cmd.Args.Add(hidden)
+	app.Cmds.Add(cmd)
+
+	model, err := helpModel(app, cmd, nil, 0)
+	assert.Nil(err)
+	assert.Equal(testCmdName, model.Name)
+	assert.Equal("myApp myCmd", model.Path)
+	assert.Equal("Does things.", model.Description)
+	assert.Equal([]IArg{arg}, model.Args)
+	assert.Equal([]HelpSection{{Title: "Mine", Args: []IArg{arg}}}, model.Sections)
+	assert.Nil(model.Topic)
+
+	model, err = helpModel(app, cmd, []string{"all"}, 0)
+	assert.Nil(err)
+	assert.True(model.All)
+	assert.Equal([]IArg{arg, hidden}, model.Args)
+
+	model, err = helpModel(app, cmd, []string{testArgName}, 0)
+	assert.Nil(err)
+	assert.Equal(arg, model.Topic)
+	assert.Empty(model.Args)
+}
+
+func TestHelpExamples(t *testing.T) {
+	assert := assert.New(t)
+
+	var out bytes.Buffer
+
+	app := AppNew(AppFields{Name: testAppName, Out: &out})
+
+	cmd, _ := CmdNew(CmdFields{
+		Name: "deploy",
+		Exec: testCmdExec,
+		Examples: []Example{
+			{Command: "deploy env=staging", Description: "Deploy to staging"},
+			{Command: "myApp deploy"},
+		},
+	})
+	app.Cmds.Add(cmd)
+
+	assert.Nil(app.Run([]string{testAppName, "deploy", "help"}))
+
+	expected := strings.Join([]string{
+		"Usage:",
+		"",
+		"myApp deploy",
+		"",
+		"Examples:",
+		"",
+		"Deploy to staging:",
+		"    myApp deploy env=staging",
+		"",
+		"    myApp deploy",
+		"",
+		"Commands:",
+	}, "\n")
+
+	assert.Contains(out.String(), expected)
+}
+
+func TestHelpCmdGroups(t *testing.T) {
+	assert := assert.New(t)
+
+	help, _ := CmdNew(CmdFields{Name: "help"})
+	status, _ := CmdNew(CmdFields{Name: "status", Group: "Cluster"})
+	logs, _ := CmdNew(CmdFields{Name: "logs", Group: "Diagnostics"})
+	deploy, _ := CmdNew(CmdFields{Name: "deploy", Group: "Cluster"})
+	misc, _ := CmdNew(CmdFields{Name: "misc"})
+	cmds := []*Cmd{help, status, logs, deploy, misc}
+
+	groups := helpCmdGroups(cmds, nil, CmdSortAdded)
+	assert.Equal([]HelpGroup{
+		{Title: "Cluster", Cmds: []*Cmd{status, deploy}},
+		{Title: "Diagnostics", Cmds: []*Cmd{logs}},
+		{Cmds: []*Cmd{help, misc}},
+	}, groups)
+
+	groups = helpCmdGroups(cmds, []string{"Diagnostics", "Unused", "Cluster"}, CmdSortName)
+	assert.Equal([]HelpGroup{
+		{Title: "Diagnostics", Cmds: []*Cmd{logs}},
+		{Title: "Cluster", Cmds: []*Cmd{deploy, status}},
+		{Cmds: []*Cmd{help, misc}},
+	}, groups)
+
+	groups = helpCmdGroups([]*Cmd{help}, []string{"Cluster"}, CmdSortAdded)
+	assert.Equal([]HelpGroup{{Cmds: []*Cmd{help}}}, groups)
+}
+
+func TestHelpCmdGroupsOutput(t *testing.T) {
+	assert := assert.New(t)
+
+	var out bytes.Buffer
+
+	app := AppNew(AppFields{Name: testAppName, Out: &out, Groups: []string{"Diagnostics"}})
+
+	deploy, _ := CmdNew(CmdFields{Name: "deploy", Group: "Cluster", Default: true, Exec: testCmdExec})
+	logs, _ := CmdNew(CmdFields{Name: "logs", Group: "Diagnostics", Exec: testCmdExec})
+	app.Cmds.Add(deploy)
+	app.Cmds.Add(logs)
+
+	assert.Nil(app.Run([]string{testAppName, "help"}))
+
+	expected := strings.Join([]string{
+		"Diagnostics:",
+		"",
+		"Name    Aliases    Description",
+		"----    -------    -----------",
+		"logs                          ",
+		"",
+		"Cluster:",
+		"",
+		"Name       Aliases    Description",
+		"----       -------    -----------",
+		"deploy*                          ",
+		"",
+		"Other commands:",
+		"",
+		"Name       Aliases    Description     ",
+		"----       -------    -----------     ",
+		"help                  Display help.   ",
+		"version               Display version.",
+		"",
+		"* indicates default command",
+		"",
+	}, "\n")
+
+	assert.True(strings.HasSuffix(out.String(), expected), out.String())
+}
+
+func TestHelpWraps(t *testing.T) {
+	assert := assert.New(t)
+
+	out := testTerminalNew(t, 50)
+
+	app := AppNew(AppFields{Name: testAppName, Out: out})
+
+	cmd, _ := CmdNew(CmdFields{
+		Name:        "deploy",
+		Description: "Deploy the current build to every cluster in every region.",
+		Exec:        testCmdExec,
+	})
+	app.Cmds.Add(cmd)
+
+	assert.Nil(app.Run([]string{testAppName, "help"}))
+
+	assert.Contains(out.String(), strings.Join([]string{
+		"deploy                Deploy the current build to ",
+		"                      every cluster in every      ",
+		"                      region.                     ",
+	}, "\n"))
+
+	for _, line := range strings.Split(out.String(), "\n") {
+		assert.LessOrEqual(len(line), 50)
+	}
+}
+
+func TestHelpFields(t *testing.T) {
+	assert := assert.New(t)
+
+	var out bytes.Buffer
+
+	app := AppNew(AppFields{Name: testAppName, Out: &out, Output: true})
+
+	cmd, _ := CmdNew(CmdFields{
+		Name:    "list",
+		Records: []*testRecord{},
+		ExecWithOutput: func(ctx *Context) (any, error) {
+			return testRecords(), nil
+		},
+	})
+	app.Cmds.Add(cmd)
+
+	assert.Nil(app.Run([]string{testAppName, "list", "help"}))
+
+	expected := strings.Join([]string{
+		"Fields:",
+		"",
+		"Name      Type    ",
+		"----      ----    ",
+		"name      string  ",
+		"status    string  ",
+		"tags      []string",
+		"",
+		"Pick fields out with a template, as in output='{{.name}}', or a path, as in output='{.name}'.",
+	}, "\n")
+
+	assert.Contains(out.String(), expected)
+
+	// Without Records, the Columns are all there is to go on.
+	cmd.Records = nil
+	cmd.Columns = []string{"name", "status"}
+
+	assert.Equal([]HelpField{{Name: "name"}, {Name: "status"}}, helpFields(cmd))
+	assert.Equal([]HelpField{{Name: "value", Type: "time.Time"}}, helpFieldsOf(reflect.TypeOf(time.Time{})))
+	assert.Nil(helpFieldsOf(reflect.TypeOf(map[string]int{})))
+
+	// Without either, there are still fields to pick out.
+	cmd.Columns = nil
+
+	out.Reset()
+	assert.Nil(app.Run([]string{testAppName, "list", "--help"}))
+	assert.Contains(out.String(), "Fields:\n\nAny field of the records returned can be picked out with a template, as in output='{{.field}}', or a path, as in output='{.field}'.\n")
+
+	// Nothing to list for a command that doesn't output records.
+	out.Reset()
+	assert.Nil(app.Run([]string{testAppName, "help"}))
+	assert.NotContains(out.String(), "Fields:")
+}
@@ -0,0 +1,291 @@
+package cligobrr
+
+import _ "embed"
+import "fmt"
+import "io"
+import "os"
+import "path/filepath"
+import "strconv"
+import "strings"
+import "text/template"
+import "time"
+
+//go:embed man.tmpl
+var manTemplateText string
+
+var manTemplate = template.Must(template.New("man").Funcs(template.FuncMap{
+	"roff":  manEscape,
+	"quote": manQuote,
+}).Parse(manTemplateText))
+
+// WriteManPages writes a man page for the app, and one for each path
+// through its commands, to dir as myApp.1, myApp-deploy.1 and so on.
+// Dir is created if need be. Hidden, deprecated and built-in commands
+// are left out, as they are from help.
+//
+// The pages are dated SOURCE_DATE_EPOCH, when it is set, so that
+// packages can be built reproducibly.
+func (self *App) WriteManPages(dir string) error {
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return err
+	}
+
+	cmds := []*Cmd{nil}
+
+	self.Cmds.Walk(func(path []*Cmd) error {
+		cmd := path[len(path)-1]
+		if !manListed(cmd) {
+			return SkipCmds
+		}
+
+		cmds = append(cmds, cmd)
+		return nil
+	})
+
+	for _, cmd := range cmds {
+		var b strings.Builder
+
+		err := self.WriteManPage(&b, cmd)
+		if err != nil {
+			return err
+		}
+
+		file := filepath.Join(dir, manPage(self, cmd)+manSuffix)
+
+		err = os.WriteFile(file, []byte(b.String()), 0o644)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WriteManPage writes the man page for cmd or, when cmd is nil,
+// for the app itself.
+func (self *App) WriteManPage(w io.Writer, cmd *Cmd) error {
+	return manTemplate.ExecuteTemplate(w, "man", manModelOf(self, cmd))
+}
+
+// manModel is what a man page is made from.
+type manModel struct {
+	Title  string
+	Date   string
+	Source string
+
+	Name        string
+	Summary     string
+	Description string
+
+	// Prefix and Fragments are the usage line, unwrapped,
+	// since man does its own wrapping.
+	Prefix    string
+	Fragments []string
+
+	Args     []manArg
+	Cmds     []manCmd
+	Examples []Example
+	Env      []manEnv
+	SeeAlso  []string
+}
+
+type manArg struct {
+	Name        string
+	Placeholder string
+	Description string
+	Details     []string
+}
+
+type manCmd struct {
+	Name        string
+	Description string
+	Page        string
+}
+
+type manEnv struct {
+	Name        string
+	Description string
+}
+
+func manModelOf(app *App, cmd *Cmd) manModel {
+	page := manPage(app, cmd)
+
+	model := manModel{
+		Title:  strings.ToUpper(page),
+		Date:   manDate(),
+		Source: strings.TrimSpace(app.Name + " " + strings.TrimSpace(app.Version)),
+		Name:   page,
+	}
+
+	var commands Cmds
+	var arguments Args
+
+	if cmd != nil {
+		model.Description = cmd.Description
+		commands = cmd.Cmds
+		arguments = cmd.Args
+
+		for _, example := range cmd.Examples {
+			model.Examples = append(model.Examples, Example{
+				Command:     exampleCommand(app, example.Command),
+				Description: strings.TrimSpace(example.Description),
+			})
+		}
+
+		model.SeeAlso = append(model.SeeAlso, manPage(app, cmd.parent))
+	} else {
+		model.Description = app.Description
+		commands = app.Cmds
+		arguments = app.Args
+	}
+
+	model.Summary, _, _ = strings.Cut(strings.TrimSpace(model.Description), "\n")
+
+	args := visibleArgs(arguments.args, false)
+	model.Prefix, model.Fragments = usageParts(app, cmd, args, true)
+
+	for _, arg := range args {
+		model.Args = append(model.Args, manArg{
+			Name:        arg.GetName(),
+			Placeholder: usagePlaceholder(arg),
+			Description: arg.GetDescription(),
+			Details:     manDetails(arg),
+		})
+
+		if len(arg.GetEnv()) > 0 {
+			model.Env = append(model.Env, manEnv{
+				Name:        arg.GetEnv(),
+				Description: fmt.Sprintf(manEnvDesc, arg.GetName()),
+			})
+		}
+	}
+
+	if cmd == nil && app.Args.get(colorArg) != nil {
+		model.Env = append(model.Env, manEnv{Name: "NO_COLOR", Description: manNoColorDesc})
+	}
+
+	for _, c := range commands.cmds {
+		if !manListed(c) {
+			continue
+		}
+
+		description := c.Description
+		if len(c.GetAliases()) > 0 {
+			description = strings.TrimSpace(fmt.Sprintf("%s (%s: %s)", description, manAliases, strings.Join(c.GetAliases(), ", ")))
+		}
+
+		model.Cmds = append(model.Cmds, manCmd{
+			Name:        c.Name,
+			Description: description,
+			Page:        manPage(app, c),
+		})
+
+		model.SeeAlso = append(model.SeeAlso, manPage(app, c))
+	}
+
+	return model
+}
+
+// manDetails are the lines listed under an arg's description.
+func manDetails(arg IArg) []string {
+	var details []string
+
+	if len(arg.GetAliases()) > 0 {
+		details = append(details, fmt.Sprintf("%s: %s", manAliases, strings.Join(arg.GetAliases(), ", ")))
+	}
+
+	details = append(details, fmt.Sprintf("%s: %s", manKind, arg.GetKind()))
+
+	if len(arg.GetDefault()) > 0 {
+		details = append(details, fmt.Sprintf("%s: %s", manDefault, arg.GetDefault()))
+	}
+
+	choices := currentChoices(arg)
+	if len(choices) > 0 {
+		details = append(details, fmt.Sprintf("%s: %s", manChoices, strings.Join(choices, ", ")))
+	}
+
+	if arg.GetMultiple() {
+		details = append(details, fmt.Sprintf(manMultiple, arg.GetSeparator()))
+	}
+
+	if arg.GetRequired() {
+		details = append(details, manRequired)
+	}
+
+	return details
+}
+
+// manListed is whether cmd gets a page of its own.
+func manListed(cmd *Cmd) bool {
+	return !cmd.builtin && !cmd.Hidden && cmd.Deprecated == nil
+}
+
+// manPage is the name of the page for cmd, like myApp-deploy-status,
+// or for the app when cmd is nil.
+func manPage(app *App, cmd *Cmd) string {
+	return strings.Join(append([]string{app.Name}, cmdNames(cmd)...), "-")
+}
+
+// manDate is SOURCE_DATE_EPOCH, if it makes sense, otherwise today.
+func manDate() string {
+	date := time.Now()
+
+	epoch, err := strconv.ParseInt(os.Getenv("SOURCE_DATE_EPOCH"), 10, 64)
+	if err == nil {
+		date = time.Unix(epoch, 0)
+	}
+
+	return date.UTC().Format(time.DateOnly)
+}
+
+// manEscape keeps text from being taken for roff: backslashes and
+// hyphens are escaped, and lines that would start with a control
+// character are made to start with nothing at all instead.
+func manEscape(text string) string {
+	text = strings.NewReplacer(`\`, `\e`, "-", `\-`).Replace(text)
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, ".") || strings.HasPrefix(line, "'") {
+			lines[i] = `\&` + line
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// manQuote makes text a single argument to a macro. Hyphens are
+// left alone, since these are things like titles and dates rather
+// than anything that might be typed.
+func manQuote(text string) string {
+	return `"` + strings.NewReplacer(`\`, `\e`, `"`, `\(dq`).Replace(text) + `"`
+}
+
+// manPagesCmdNew makes the hidden command AppFields.ManPages adds.
+func manPagesCmdNew(app *App) *Cmd {
+	cmd, _ := CmdNew(CmdFields{
+		Name:        manPagesName,
+		Description: manPagesDesc,
+		Hidden:      true,
+		ExecWithContext: func(ctx *Context) error {
+			dir, err := ctx.Args.AsString(manPagesDirArg)
+			if err != nil {
+				return err
+			}
+
+			return app.WriteManPages(dir)
+		},
+	})
+
+	dir, _ := StringArgNew(ArgFields{
+		Name:        manPagesDirArg,
+		Description: manPagesDirDesc,
+		Default:     ".",
+	})
+
+	cmd.Args.Add(dir)
+
+	return cmd
+}
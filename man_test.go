@@ -0,0 +1,153 @@
+package cligobrr
+
+import "bytes"
+import "os"
+import "path/filepath"
+import "strings"
+import "testing"
+import "github.com/stretchr/testify/assert"
+
+func testManApp() (*App, *Cmd) {
+	app := AppNew(AppFields{
+		Name:        testAppName,
+		Version:     "1.2.3",
+		Description: "Manage services.",
+		Color:       true,
+		ManPages:    true,
+	})
+
+	deploy, _ := CmdNew(CmdFields{
+		Name:        "deploy",
+		Alias:       "d",
+		Description: "Deploy a service.",
+		Exec:        testCmdExec,
+		Examples:    []Example{{Command: "deploy region=eu", Description: "Deploy to eu"}},
+	})
+
+	region, _ := StringArgNew(ArgFields{
+		Name:        "region",
+		Alias:       "r",
+		Description: "Where to deploy.",
+		Choices:     []string{"us", "eu"},
+		Default:     "us",
+		Env:         "REGION",
+	})
+
+	tags, _ := StringArgNew(ArgFields{Name: "tags", Multiple: true, Required: true})
+	secret, _ := StringArgNew(ArgFields{Name: "secret", Hidden: true})
+
+	deploy.Args.Add(region)
+	deploy.Args.Add(tags)
+	deploy.Args.Add(secret)
+
+	status, _ := CmdNew(CmdFields{Name: "status", Exec: testCmdExec})
+	deploy.Cmds.Add(status)
+
+	internal, _ := CmdNew(CmdFields{Name: "internal", Hidden: true, Exec: testCmdExec})
+	internalSub, _ := CmdNew(CmdFields{Name: "sub", Exec: testCmdExec})
+	internal.Cmds.Add(internalSub)
+
+	app.Cmds.Add(deploy)
+	app.Cmds.Add(internal)
+
+	return app, deploy
+}
+
+func TestWriteManPage(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Setenv("SOURCE_DATE_EPOCH", "1700000000")
+
+	app, deploy := testManApp()
+
+	var out bytes.Buffer
+	assert.Nil(app.WriteManPage(&out, deploy))
+
+	expected := strings.Join([]string{
+		`.TH "MYAPP-DEPLOY" "1" "2023-11-14" "myApp 1.2.3" "User Commands"`,
+		`.SH NAME`,
+		`myApp\-deploy \- Deploy a service.`,
+		`.SH SYNOPSIS`,
+		`\fBmyApp [global args] deploy\fR [region=<us|eu>] tags=<string>,... [<command>]`,
+		`.SH DESCRIPTION`,
+		`Deploy a service.`,
+		`.SH OPTIONS`,
+		`.TP`,
+		`\fBregion\fR=\fIus|eu\fR`,
+		`Where to deploy.`,
+		`.br`,
+		`Aliases: r`,
+		`.br`,
+		`Kind: string`,
+		`.br`,
+		`Default: us`,
+		`.br`,
+		`Choices: us, eu`,
+		`.TP`,
+		`\fBtags\fR=\fIstring\fR`,
+		`.br`,
+		`Kind: string`,
+		`.br`,
+		`Takes more than one value, separated by ",".`,
+		`.br`,
+		`Required.`,
+		`.SH COMMANDS`,
+		`.TP`,
+		`\fBstatus\fR`,
+		`See \fBmyApp\-deploy\-status\fR(1).`,
+		`.SH EXAMPLES`,
+		`.PP`,
+		`Deploy to eu:`,
+		`.RS 4`,
+		`.nf`,
+		`myApp deploy region=eu`,
+		`.fi`,
+		`.RE`,
+		`.SH ENVIRONMENT`,
+		`.TP`,
+		`.B REGION`,
+		`Used for region when it isn't given.`,
+		`.SH SEE ALSO`,
+		`\fBmyApp\fR(1), \fBmyApp\-deploy\-status\fR(1)`,
+		``,
+	}, "\n")
+
+	assert.Equal(expected, out.String())
+
+	out.Reset()
+	assert.Nil(app.WriteManPage(&out, nil))
+
+	page := out.String()
+	assert.Contains(page, "myApp \\- Manage services.\n")
+	assert.Contains(page, ".TP\n\\fBdeploy\\fR\nDeploy a service. (Aliases: d)\n.br\nSee \\fBmyApp\\-deploy\\fR(1).\n")
+	assert.Contains(page, ".B NO_COLOR\n")
+	assert.NotContains(page, "internal")
+	assert.NotContains(page, "__man")
+}
+
+func TestWriteManPages(t *testing.T) {
+	assert := assert.New(t)
+
+	app, _ := testManApp()
+	dir := filepath.Join(t.TempDir(), "man")
+
+	assert.Nil(app.Run([]string{testAppName, "__man", "dir=" + dir}))
+
+	entries, err := os.ReadDir(dir)
+	assert.Nil(err)
+
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+
+	assert.Equal([]string{"myApp-deploy-status.1", "myApp-deploy.1", "myApp.1"}, names)
+}
+
+func TestManEscape(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(`a\-b \ec`, manEscape(`a-b \c`))
+	assert.Equal("\\&.start\n\\&'quote\nfine", manEscape(".start\n'quote\nfine"))
+	assert.Equal(`"say \(dqhi\(dq \e 1-2"`, manQuote(`say "hi" \ 1-2`))
+}
@@ -0,0 +1,556 @@
+package cligobrr
+
+import "bytes"
+import "encoding/csv"
+import "encoding/json"
+import "fmt"
+import "io"
+import "reflect"
+import "slices"
+import "strings"
+import "text/template"
+
+// The formats Output can be written in.
+const (
+	OutputTable    = "table"
+	OutputJSON     = "json"
+	OutputYAML     = "yaml"
+	OutputCSV      = "csv"
+	OutputTSV      = "tsv"
+	OutputMarkdown = "markdown"
+)
+
+// outputWriters has a writer for each format, in the order
+// the formats are offered as choices.
+var outputWriters = []struct {
+	format string
+	write  func(io.Writer, *Output) error
+}{
+	{OutputTable, writeTable},
+	{OutputJSON, writeJSON},
+	{OutputYAML, writeYAML},
+	{OutputCSV, writeCSV},
+	{OutputTSV, writeTSV},
+	{OutputMarkdown, writeMarkdown},
+}
+
+// outputFormats returns the name of every format.
+func outputFormats() []string {
+	var formats []string
+
+	for _, writer := range outputWriters {
+		formats = append(formats, writer.format)
+	}
+
+	return formats
+}
+
+// Output is records, whatever a command returned, turned into columns
+// and rows so they can be written in any of the formats.
+type Output struct {
+	Columns []string
+	Rows    [][]any
+
+	// Single is set when a single record was given rather than a
+	// slice of them, so formats that can tell the difference do.
+	Single bool
+
+	// colors styles a table's headers. Other formats are
+	// left alone, since they're meant to be read by programs.
+	colors palette
+
+	// app, if there is one, decides how mistakes are met
+	// with suggestions.
+	app *App
+}
+
+// OutputOf turns records into Output. Records can be a struct, a map
+// with string keys, or a slice of either, or pointers to any of them.
+// A struct's exported fields become columns, named by an `output`
+// tag, else a `json` tag, else the field name; a tag of "-" leaves
+// the field out. A map's keys become columns in sorted order. Anything
+// else is a single column named "value".
+func OutputOf(records any) (*Output, error) {
+	output := Output{}
+
+	value := outputIndirect(reflect.ValueOf(records))
+	if !value.IsValid() {
+		return &output, nil
+	}
+
+	if value.Kind() == reflect.Slice || value.Kind() == reflect.Array {
+		for i := 0; i < value.Len(); i++ {
+			err := output.add(value.Index(i))
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return &output, nil
+	}
+
+	output.Single = true
+
+	err := output.add(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &output, nil
+}
+
+func (self *Output) add(value reflect.Value) error {
+	record, err := outputRecord(value)
+	if err != nil {
+		return err
+	}
+
+	row := make([]any, len(self.Columns))
+
+	for i, column := range record.keys {
+		j := slices.Index(self.Columns, column)
+		if j < 0 {
+			// Records don't all have to have the same columns,
+			// so earlier rows are left empty for new ones.
+			self.Columns = append(self.Columns, column)
+			for k := range self.Rows {
+				self.Rows[k] = append(self.Rows[k], nil)
+			}
+
+			row = append(row, nil)
+			j = len(self.Columns) - 1
+		}
+
+		row[j] = record.values[i]
+	}
+
+	self.Rows = append(self.Rows, row)
+
+	return nil
+}
+
+// Select keeps only the given columns, in the order given. With no
+// rows, there's nothing to say a column doesn't exist, so any will do.
+func (self *Output) Select(columns []string) error {
+	var indexes []int
+
+	for _, column := range columns {
+		i := slices.Index(self.Columns, column)
+		if i < 0 && len(self.Rows) > 0 {
+			return errUnknownColumn(column, self.app.suggest(column, self.Columns))
+		}
+
+		indexes = append(indexes, i)
+	}
+
+	var rows [][]any
+
+	for _, row := range self.Rows {
+		var selected []any
+		for _, i := range indexes {
+			selected = append(selected, row[i])
+		}
+
+		rows = append(rows, selected)
+	}
+
+	self.Columns = slices.Clone(columns)
+	self.Rows = rows
+
+	return nil
+}
+
+// Write writes the output to w in the given format: one of the named
+// formats, a template like '{{.name}}' or a path like '{.name}'. The
+// prefixes 'template:' and 'path:' can be used to leave no doubt.
+func (self *Output) Write(w io.Writer, format string) error {
+	write, err := outputWriter(self.app, format)
+	if err != nil {
+		return err
+	}
+
+	return write(w, self)
+}
+
+func outputWriter(app *App, format string) (func(io.Writer, *Output) error, error) {
+	switch {
+	case strings.HasPrefix(format, outputTemplatePrefix):
+		return outputTemplate(strings.TrimPrefix(format, outputTemplatePrefix))
+	case strings.HasPrefix(format, outputPathPrefix):
+		return outputPath(strings.TrimPrefix(format, outputPathPrefix))
+	case strings.Contains(format, "{{"):
+		return outputTemplate(format)
+	case strings.HasPrefix(format, "{") || strings.HasPrefix(format, "$"):
+		return outputPath(format)
+	}
+
+	for _, writer := range outputWriters {
+		if writer.format == format {
+			return writer.write, nil
+		}
+	}
+
+	return nil, errUnknownOutputFormat(format, app.suggest(format, outputFormats()))
+}
+
+// outputExpression reports whether format is a template or a path,
+// rather than the name of one of the formats.
+func outputExpression(format string) bool {
+	return !slices.Contains(outputFormats(), format)
+}
+
+// outputTemplate parses text and returns a writer that executes it
+// for each record in turn, with the record's columns as its fields.
+// Every record ends up on its own line. Since templates are usually
+// typed in a shell, \t and \n are turned into tabs and newlines.
+func outputTemplate(text string) (func(io.Writer, *Output) error, error) {
+	text = outputUnescape.Replace(text)
+
+	tmpl, err := template.New(outputArg).Funcs(outputFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, errOutputTemplate(err, nil)
+	}
+
+	return func(w io.Writer, output *Output) error {
+		for _, row := range output.Rows {
+			record := map[string]any{}
+			for i, column := range output.Columns {
+				record[column] = row[i]
+			}
+
+			var line bytes.Buffer
+
+			err := tmpl.Execute(&line, record)
+			if err != nil {
+				return errOutputTemplate(err, output.Columns)
+			}
+
+			// Records the template has nothing to say about,
+			// like those an if leaves out, don't get a line.
+			if line.Len() == 0 {
+				continue
+			}
+
+			if !bytes.HasSuffix(line.Bytes(), []byte("\n")) {
+				line.WriteString("\n")
+			}
+
+			_, err = line.WriteTo(w)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}, nil
+}
+
+var outputUnescape = strings.NewReplacer(`\\`, `\`, `\t`, "\t", `\n`, "\n")
+
+// outputFuncs are available to output templates.
+var outputFuncs = template.FuncMap{
+	"join": strings.Join,
+	"json": func(value any) (string, error) {
+		encoded, err := json.Marshal(value)
+		return string(encoded), err
+	},
+	"cell": outputCell,
+}
+
+// outputMap is a record's columns and values, in order.
+type outputMap struct {
+	keys   []string
+	values []any
+}
+
+// MarshalJSON keeps the keys in order, which a map wouldn't.
+func (self outputMap) MarshalJSON() ([]byte, error) {
+	var encoded bytes.Buffer
+
+	encoded.WriteString("{")
+
+	for i, key := range self.keys {
+		if i > 0 {
+			encoded.WriteString(",")
+		}
+
+		k, _ := json.Marshal(key)
+		v, err := json.Marshal(self.values[i])
+		if err != nil {
+			return nil, err
+		}
+
+		encoded.Write(k)
+		encoded.WriteString(":")
+		encoded.Write(v)
+	}
+
+	encoded.WriteString("}")
+
+	return encoded.Bytes(), nil
+}
+
+func outputRecord(value reflect.Value) (outputMap, error) {
+	value = outputIndirect(value)
+	record := outputMap{}
+
+	switch {
+	case !value.IsValid():
+		record.keys = []string{outputValueColumn}
+		record.values = []any{nil}
+	case value.Kind() == reflect.Struct && !outputScalar(value):
+		return outputStruct(value), nil
+	case value.Kind() == reflect.Map && value.Type().Key().Kind() == reflect.String:
+		return outputMapOf(value), nil
+	case value.Kind() == reflect.Func || value.Kind() == reflect.Chan || value.Kind() == reflect.UnsafePointer:
+		return record, errUnsupportedOutput(value.Kind().String())
+	default:
+		record.keys = []string{outputValueColumn}
+		record.values = []any{value.Interface()}
+	}
+
+	return record, nil
+}
+
+func outputStruct(value reflect.Value) outputMap {
+	record := outputMap{}
+
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			embedded := outputStruct(value.Field(i))
+			record.keys = append(record.keys, embedded.keys...)
+			record.values = append(record.values, embedded.values...)
+			continue
+		}
+
+		name := outputFieldName(field)
+		if name == "-" {
+			continue
+		}
+
+		record.keys = append(record.keys, name)
+		record.values = append(record.values, value.Field(i).Interface())
+	}
+
+	return record
+}
+
+func outputFieldName(field reflect.StructField) string {
+	for _, key := range []string{"output", "json"} {
+		tag, ok := field.Tag.Lookup(key)
+		if !ok {
+			continue
+		}
+
+		name, _, _ := strings.Cut(tag, ",")
+		if len(name) > 0 {
+			return name
+		}
+	}
+
+	return field.Name
+}
+
+func outputMapOf(value reflect.Value) outputMap {
+	record := outputMap{}
+
+	keys := value.MapKeys()
+	slices.SortFunc(keys, func(a, b reflect.Value) int {
+		return strings.Compare(a.String(), b.String())
+	})
+
+	for _, key := range keys {
+		record.keys = append(record.keys, key.String())
+		record.values = append(record.values, value.MapIndex(key).Interface())
+	}
+
+	return record
+}
+
+// outputScalar reports whether a value is better shown as it
+// describes itself, like a time.Time, than picked apart.
+func outputScalar(value reflect.Value) bool {
+	if !value.CanInterface() {
+		return false
+	}
+
+	switch value.Interface().(type) {
+	case fmt.Stringer, error:
+		return true
+	}
+
+	return false
+}
+
+func outputIndirect(value reflect.Value) reflect.Value {
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return reflect.Value{}
+		}
+
+		value = value.Elem()
+	}
+
+	return value
+}
+
+// outputCell is how a value is shown where there's only room for
+// text, like a table cell. Lists are joined, and anything with more
+// structure than that is shown as JSON.
+func outputCell(value any) string {
+	// A nil pointer can still be a Stringer, like a nil *time.Time,
+	// but asking it to describe itself would panic.
+	rv := outputIndirect(reflect.ValueOf(value))
+	if !rv.IsValid() {
+		return ""
+	}
+
+	switch v := value.(type) {
+	case string:
+		return v
+	case fmt.Stringer:
+		return v.String()
+	case error:
+		return v.Error()
+	}
+
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		var cells []string
+		for i := 0; i < rv.Len(); i++ {
+			cells = append(cells, outputCell(rv.Index(i).Interface()))
+		}
+
+		return strings.Join(cells, ", ")
+	case reflect.Map, reflect.Struct:
+		encoded, err := json.Marshal(rv.Interface())
+		if err == nil {
+			return string(encoded)
+		}
+	}
+
+	return fmt.Sprint(rv.Interface())
+}
+
+func (self *Output) cells(row []any) []string {
+	var cells []string
+
+	for _, value := range row {
+		cells = append(cells, outputCell(value))
+	}
+
+	return cells
+}
+
+func writeTable(w io.Writer, output *Output) error {
+	if len(output.Columns) == 0 {
+		return nil
+	}
+
+	tableFields := TableFields{
+		Cols:    uint8(len(output.Columns)),
+		Headers: output.Columns,
+		Width:   terminalWidth(w),
+		Paint:   output.colors.headings,
+	}
+
+	table, err := TableNew(tableFields)
+	if err != nil {
+		return err
+	}
+
+	for _, row := range output.Rows {
+		table.Add(output.cells(row))
+	}
+
+	_, err = table.WriteTo(w)
+
+	return err
+}
+
+func writeJSON(w io.Writer, output *Output) error {
+	records := []outputMap{}
+
+	for _, row := range output.Rows {
+		records = append(records, outputMap{keys: output.Columns, values: row})
+	}
+
+	var value any = records
+	if output.Single {
+		value = nil
+		if len(records) == 1 {
+			value = records[0]
+		}
+	}
+
+	encoded, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(w, string(encoded))
+
+	return err
+}
+
+func writeCSV(w io.Writer, output *Output) error {
+	return writeDelimited(w, output, ',')
+}
+
+func writeTSV(w io.Writer, output *Output) error {
+	return writeDelimited(w, output, '\t')
+}
+
+func writeDelimited(w io.Writer, output *Output, comma rune) error {
+	if len(output.Columns) == 0 {
+		return nil
+	}
+
+	writer := csv.NewWriter(w)
+	writer.Comma = comma
+
+	writer.Write(output.Columns)
+	for _, row := range output.Rows {
+		writer.Write(output.cells(row))
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
+
+func writeMarkdown(w io.Writer, output *Output) error {
+	if len(output.Columns) == 0 {
+		return nil
+	}
+
+	escape := strings.NewReplacer("|", `\|`, "\r\n", "<br>", "\n", "<br>")
+
+	line := func(cells []string) string {
+		var escaped []string
+		for _, cell := range cells {
+			escaped = append(escaped, escape.Replace(cell))
+		}
+
+		return fmt.Sprintf("| %s |", strings.Join(escaped, " | "))
+	}
+
+	var separators []string
+	for range output.Columns {
+		separators = append(separators, "---")
+	}
+
+	lines := []string{line(output.Columns), line(separators)}
+	for _, row := range output.Rows {
+		lines = append(lines, line(output.cells(row)))
+	}
+
+	_, err := fmt.Fprintln(w, strings.Join(lines, "\n"))
+
+	return err
+}
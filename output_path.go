@@ -0,0 +1,396 @@
+package cligobrr
+
+import "encoding/json"
+import "fmt"
+import "io"
+import "reflect"
+import "slices"
+import "strconv"
+import "strings"
+
+type pathStep struct {
+	kind    pathKind
+	name    string
+	start   int
+	end     int
+	bounded [2]bool
+	descend bool
+}
+
+type pathKind int
+
+const (
+	pathField pathKind = iota
+	pathWildcard
+	pathIndex
+	pathSlice
+)
+
+// outputPath parses expr and returns a writer for it. A path picks values out of records, a little like JSONPath or jq:
+//
+//	{.name}          every record's name
+//	.tags[0]         the first of every record's tags
+//	[1:3].name       the names of the second and third records
+//	..id             every id, however deeply nested
+//	$[*].limits.*    every value in every record's limits
+//
+// The surrounding braces and the leading $ are optional. A field of a
+// list of records is that field of each of them. Each value found is
+// written on its own line, with anything other than a plain value
+// written as JSON.
+func outputPath(expr string) (func(io.Writer, *Output) error, error) {
+	steps, err := pathParse(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(w io.Writer, output *Output) error {
+		for _, value := range pathEval(steps, pathRoot(output)) {
+			line, err := pathString(value)
+			if err != nil {
+				return err
+			}
+
+			_, err = fmt.Fprintln(w, line)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}, nil
+}
+
+func pathRoot(output *Output) any {
+	var records []any
+
+	for _, row := range output.Rows {
+		records = append(records, outputMap{keys: output.Columns, values: row})
+	}
+
+	if output.Single && len(records) == 1 {
+		return records[0]
+	}
+
+	return records
+}
+
+func pathString(value any) (string, error) {
+	rv := outputIndirect(reflect.ValueOf(value))
+	if !rv.IsValid() {
+		return "", nil
+	}
+
+	_, mapping := pathMapping(value)
+	_, sequence := pathSequence(value)
+	if !mapping && !sequence {
+		return outputCell(value), nil
+	}
+
+	encoded, err := json.Marshal(value)
+
+	return string(encoded), err
+}
+
+// pathParser keeps track of where it is in the expression, so
+// that errors can point at exactly what was wrong.
+type pathParser struct {
+	expr string
+	pos  int
+	end  int
+}
+
+func pathParse(expr string) ([]pathStep, error) {
+	parser := pathParser{expr: expr}
+
+	trimmed := strings.TrimSpace(expr)
+	parser.pos = strings.Index(expr, trimmed)
+	parser.end = parser.pos + len(trimmed)
+
+	if strings.HasPrefix(trimmed, "{") {
+		if !strings.HasSuffix(trimmed, "}") {
+			return nil, parser.fail(parser.end, "missing closing }")
+		}
+
+		parser.pos++
+		parser.end--
+	}
+
+	if parser.peek() == '$' {
+		parser.pos++
+	}
+
+	var steps []pathStep
+
+	for parser.pos < parser.end {
+		step, err := parser.step()
+		if err != nil {
+			return nil, err
+		}
+
+		steps = append(steps, step)
+	}
+
+	return steps, nil
+}
+
+func (self *pathParser) peek() byte {
+	if self.pos >= self.end {
+		return 0
+	}
+
+	return self.expr[self.pos]
+}
+
+func (self *pathParser) fail(pos int, reason string) error {
+	return errOutputPath(self.expr, pos, reason)
+}
+
+func (self *pathParser) step() (pathStep, error) {
+	switch self.peek() {
+	case '[':
+		return self.bracket(false)
+	case '.':
+		self.pos++
+	default:
+		return pathStep{}, self.fail(self.pos, fmt.Sprintf("unexpected %q", self.peek()))
+	}
+
+	descend := false
+	if self.peek() == '.' {
+		descend = true
+		self.pos++
+	}
+
+	switch self.peek() {
+	case '*':
+		self.pos++
+		return pathStep{kind: pathWildcard, descend: descend}, nil
+	case '[':
+		return self.bracket(descend)
+	}
+
+	start := self.pos
+	for self.pos < self.end && pathNameByte(self.expr[self.pos]) {
+		self.pos++
+	}
+
+	if self.pos == start {
+		return pathStep{}, self.fail(self.pos, "expected a field name")
+	}
+
+	return pathStep{kind: pathField, name: self.expr[start:self.pos], descend: descend}, nil
+}
+
+func pathNameByte(b byte) bool {
+	return b == '_' || b == '-' || b >= 0x80 ||
+		(b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || (b >= '0' && b <= '9')
+}
+
+// bracket parses [*], [n], [start:end] and ['name'].
+func (self *pathParser) bracket(descend bool) (pathStep, error) {
+	open := self.pos
+	self.pos++
+
+	end := strings.IndexByte(self.expr[self.pos:self.end], ']')
+	if end < 0 {
+		return pathStep{}, self.fail(open, "missing closing ]")
+	}
+
+	inside := self.expr[self.pos : self.pos+end]
+	step := pathStep{descend: descend}
+
+	switch {
+	case inside == "*":
+		step.kind = pathWildcard
+	case len(inside) >= 2 && (inside[0] == '\'' || inside[0] == '"') && inside[len(inside)-1] == inside[0]:
+		step.kind = pathField
+		step.name = inside[1 : len(inside)-1]
+	case strings.Contains(inside, ":"):
+		step.kind = pathSlice
+
+		from, to, _ := strings.Cut(inside, ":")
+		for i, bound := range []string{from, to} {
+			bound = strings.TrimSpace(bound)
+			if len(bound) == 0 {
+				continue
+			}
+
+			n, err := strconv.Atoi(bound)
+			if err != nil {
+				return pathStep{}, self.fail(self.pos, fmt.Sprintf("%q isn't a number", bound))
+			}
+
+			step.bounded[i] = true
+			if i == 0 {
+				step.start = n
+			} else {
+				step.end = n
+			}
+		}
+	default:
+		n, err := strconv.Atoi(strings.TrimSpace(inside))
+		if err != nil {
+			return pathStep{}, self.fail(self.pos, fmt.Sprintf("%q isn't an index, a slice, * or a quoted name", inside))
+		}
+
+		step.kind = pathIndex
+		step.start = n
+	}
+
+	self.pos += end + 1
+
+	return step, nil
+}
+
+func pathEval(steps []pathStep, root any) []any {
+	current := []any{root}
+
+	for _, step := range steps {
+		var next []any
+
+		for _, value := range current {
+			targets := []any{value}
+			if step.descend {
+				targets = pathDescendants(value)
+			}
+
+			for _, target := range targets {
+				next = append(next, step.apply(target, !step.descend)...)
+			}
+		}
+
+		current = next
+	}
+
+	return current
+}
+
+// apply returns whatever the step picks out of value. When spread is
+// set, a field of a list is that field of each thing in the list.
+func (self pathStep) apply(value any, spread bool) []any {
+	switch self.kind {
+	case pathField:
+		if mapping, ok := pathMapping(value); ok {
+			i := slices.Index(mapping.keys, self.name)
+			if i < 0 {
+				return nil
+			}
+
+			return []any{mapping.values[i]}
+		}
+
+		if items, ok := pathSequence(value); ok && spread {
+			var found []any
+			for _, item := range items {
+				found = append(found, self.apply(item, false)...)
+			}
+
+			return found
+		}
+	case pathWildcard:
+		if mapping, ok := pathMapping(value); ok {
+			return mapping.values
+		}
+
+		if items, ok := pathSequence(value); ok {
+			return items
+		}
+	case pathIndex:
+		if items, ok := pathSequence(value); ok {
+			i := self.start
+			if i < 0 {
+				i += len(items)
+			}
+
+			if i >= 0 && i < len(items) {
+				return []any{items[i]}
+			}
+		}
+	case pathSlice:
+		if items, ok := pathSequence(value); ok {
+			start, end := 0, len(items)
+			if self.bounded[0] {
+				start = pathBound(self.start, len(items))
+			}
+
+			if self.bounded[1] {
+				end = pathBound(self.end, len(items))
+			}
+
+			if start < end {
+				return items[start:end]
+			}
+		}
+	}
+
+	return nil
+}
+
+// pathBound turns a slice bound, which can count back from the end,
+// into an index between 0 and n.
+func pathBound(bound int, n int) int {
+	if bound < 0 {
+		bound += n
+	}
+
+	return max(0, min(bound, n))
+}
+
+// pathDescendants returns value and everything beneath it.
+func pathDescendants(value any) []any {
+	found := []any{value}
+
+	var children []any
+	if mapping, ok := pathMapping(value); ok {
+		children = mapping.values
+	} else if items, ok := pathSequence(value); ok {
+		children = items
+	}
+
+	for _, child := range children {
+		found = append(found, pathDescendants(child)...)
+	}
+
+	return found
+}
+
+func pathMapping(value any) (outputMap, bool) {
+	if mapping, ok := value.(outputMap); ok {
+		return mapping, true
+	}
+
+	rv := outputIndirect(reflect.ValueOf(value))
+
+	switch {
+	case !rv.IsValid():
+	case rv.Kind() == reflect.Struct && !outputScalar(rv):
+		return outputStruct(rv), true
+	case rv.Kind() == reflect.Map && rv.Type().Key().Kind() == reflect.String:
+		return outputMapOf(rv), true
+	}
+
+	return outputMap{}, false
+}
+
+func pathSequence(value any) ([]any, bool) {
+	rv := outputIndirect(reflect.ValueOf(value))
+	if !rv.IsValid() {
+		return nil, false
+	}
+
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, false
+	}
+
+	if rv.Type().Elem().Kind() == reflect.Uint8 {
+		return nil, false
+	}
+
+	var items []any
+	for i := 0; i < rv.Len(); i++ {
+		items = append(items, rv.Index(i).Interface())
+	}
+
+	return items, true
+}
@@ -0,0 +1,90 @@
+package cligobrr
+
+import "bytes"
+import "strings"
+import "testing"
+import "github.com/stretchr/testify/assert"
+
+func testPath(t *testing.T, records any, expr string) string {
+	var out bytes.Buffer
+
+	output, err := OutputOf(records)
+	assert.Nil(t, err)
+	assert.Nil(t, output.Write(&out, expr))
+
+	return out.String()
+}
+
+func TestOutputPath(t *testing.T) {
+	assert := assert.New(t)
+
+	records := testRecords()
+
+	assert.Equal("api\ndb\n", testPath(t, records, "{.name}"))
+	assert.Equal("api\ndb\n", testPath(t, records, "$.name"))
+	assert.Equal("api\ndb\n", testPath(t, records, "path:.name"))
+	assert.Equal("api\n", testPath(t, records, "{[0].name}"))
+	assert.Equal("db\n", testPath(t, records, "{[-1].name}"))
+	assert.Equal("db\n", testPath(t, records, "{[1:].name}"))
+	assert.Equal("api\n", testPath(t, records, "{[:-1].name}"))
+	assert.Equal("web\n", testPath(t, records, "{.tags[0]}"))
+	assert.Equal("web\nprod\n", testPath(t, records, "{[0].tags[*]}"))
+	assert.Equal("up\ndown\n", testPath(t, records, "{['status']}"))
+	assert.Equal(`{"name":"db","status":"down","tags":null}`+"\n", testPath(t, records, "{[1]}"))
+	assert.Equal("", testPath(t, records, "{.nope}"))
+	assert.Equal("", testPath(t, records, "{[5]}"))
+
+	// A single record is the root rather than a list of one.
+	assert.Equal("api\n", testPath(t, records[0], "{.name}"))
+	assert.Equal("web\nprod\n", testPath(t, records[0], "{.tags.*}"))
+}
+
+func TestOutputPathDescent(t *testing.T) {
+	type limits struct {
+		CPU    int `json:"cpu"`
+		Memory int `json:"memory"`
+	}
+
+	type service struct {
+		ID     string `json:"id"`
+		Limits limits `json:"limits"`
+	}
+
+	records := []service{
+		{ID: "a", Limits: limits{CPU: 1, Memory: 256}},
+		{ID: "b", Limits: limits{CPU: 2, Memory: 512}},
+	}
+
+	assert := assert.New(t)
+
+	assert.Equal("a\nb\n", testPath(t, records, "{..id}"))
+	assert.Equal("1\n2\n", testPath(t, records, "{..cpu}"))
+	assert.Equal("1\n256\n2\n512\n", testPath(t, records, "$[*].limits.*"))
+}
+
+func TestOutputPathErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	output, _ := OutputOf(testRecords())
+
+	err := output.Write(&bytes.Buffer{}, "{.tags[0}")
+	expected := strings.Join([]string{
+		"Invalid output path: missing closing ] at column 7.",
+		"",
+		"    {.tags[0}",
+		"          ^",
+	}, "\n")
+	assert.EqualError(err, expected)
+
+	err = output.Write(&bytes.Buffer{}, "{.name")
+	assert.ErrorContains(err, "missing closing } at column 7.")
+
+	err = output.Write(&bytes.Buffer{}, "{.tags[x]}")
+	assert.ErrorContains(err, `"x" isn't an index, a slice, * or a quoted name at column 8.`)
+
+	err = output.Write(&bytes.Buffer{}, "{.}")
+	assert.ErrorContains(err, "expected a field name at column 3.")
+
+	err = output.Write(&bytes.Buffer{}, "$name")
+	assert.ErrorContains(err, `unexpected 'n' at column 2.`)
+}
@@ -0,0 +1,334 @@
+package cligobrr
+
+import "bytes"
+import "strings"
+import "testing"
+import "time"
+import "github.com/stretchr/testify/assert"
+
+type testRecord struct {
+	Name    string   `json:"name"`
+	Status  string   `output:"status"`
+	Tags    []string `json:"tags,omitempty"`
+	Secret  string   `json:"-"`
+	private string
+}
+
+func testRecords() []testRecord {
+	return []testRecord{
+		{Name: "api", Status: "up", Tags: []string{"web", "prod"}, private: "x"},
+		{Name: "db", Status: "down"},
+	}
+}
+
+func TestOutputOfStructs(t *testing.T) {
+	assert := assert.New(t)
+
+	output, err := OutputOf(testRecords())
+	assert.Nil(err)
+	assert.False(output.Single)
+	assert.Equal([]string{"name", "status", "tags"}, output.Columns)
+	assert.Equal([]any{"api", "up", []string{"web", "prod"}}, output.Rows[0])
+	assert.Equal([]any{"db", "down", []string(nil)}, output.Rows[1])
+
+	output, err = OutputOf(&testRecords()[0])
+	assert.Nil(err)
+	assert.True(output.Single)
+	assert.Equal(1, len(output.Rows))
+}
+
+func TestOutputOfMaps(t *testing.T) {
+	assert := assert.New(t)
+
+	records := []map[string]any{
+		{"name": "api", "port": 80},
+		{"name": "db", "replicas": 3},
+	}
+
+	output, err := OutputOf(records)
+	assert.Nil(err)
+	assert.Equal([]string{"name", "port", "replicas"}, output.Columns)
+	assert.Equal([]any{"api", 80, nil}, output.Rows[0])
+	assert.Equal([]any{"db", nil, 3}, output.Rows[1])
+}
+
+func TestOutputOfScalars(t *testing.T) {
+	assert := assert.New(t)
+
+	output, err := OutputOf([]string{"a", "b"})
+	assert.Nil(err)
+	assert.Equal([]string{"value"}, output.Columns)
+	assert.Equal([][]any{{"a"}, {"b"}}, output.Rows)
+
+	when := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	output, err = OutputOf(when)
+	assert.Nil(err)
+	assert.Equal([][]any{{when}}, output.Rows)
+
+	output, err = OutputOf(nil)
+	assert.Nil(err)
+	assert.Empty(output.Columns)
+
+	_, err = OutputOf(func() {})
+	assert.EqualError(err, "Can't output records of kind func.")
+}
+
+func TestOutputSelect(t *testing.T) {
+	assert := assert.New(t)
+
+	output, _ := OutputOf(testRecords())
+
+	assert.Nil(output.Select([]string{"status", "name"}))
+	assert.Equal([]string{"status", "name"}, output.Columns)
+	assert.Equal([][]any{{"up", "api"}, {"down", "db"}}, output.Rows)
+
+	err := output.Select([]string{"nmae"})
+	assert.EqualError(err, "Unknown column: nmae. Did you mean: name?")
+
+	empty, _ := OutputOf([]testRecord{})
+	assert.Nil(empty.Select([]string{"anything"}))
+}
+
+func testOutput(t *testing.T, format string) string {
+	var out bytes.Buffer
+
+	output, err := OutputOf(testRecords())
+	assert.Nil(t, err)
+	assert.Nil(t, output.Write(&out, format))
+
+	return out.String()
+}
+
+func TestOutputTable(t *testing.T) {
+	expected := strings.Join([]string{
+		"name    status    tags     ",
+		"----    ------    ----     ",
+		"api     up        web, prod",
+		"db      down               ",
+		"",
+	}, "\n")
+
+	assert.Equal(t, expected, testOutput(t, OutputTable))
+}
+
+func TestOutputJSON(t *testing.T) {
+	assert := assert.New(t)
+
+	expected := strings.Join([]string{
+		`[`,
+		`  {`,
+		`    "name": "api",`,
+		`    "status": "up",`,
+		`    "tags": [`,
+		`      "web",`,
+		`      "prod"`,
+		`    ]`,
+		`  },`,
+		`  {`,
+		`    "name": "db",`,
+		`    "status": "down",`,
+		`    "tags": null`,
+		`  }`,
+		`]`,
+		``,
+	}, "\n")
+
+	assert.Equal(expected, testOutput(t, OutputJSON))
+
+	var out bytes.Buffer
+
+	output, _ := OutputOf(map[string]int{"b": 2, "a": 1})
+	assert.Nil(output.Write(&out, OutputJSON))
+	assert.Equal("{\n  \"a\": 1,\n  \"b\": 2\n}\n", out.String())
+
+	out.Reset()
+	output, _ = OutputOf([]int{})
+	assert.Nil(output.Write(&out, OutputJSON))
+	assert.Equal("[]\n", out.String())
+}
+
+func TestOutputCSV(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("name,status,tags\napi,up,\"web, prod\"\ndb,down,\n", testOutput(t, OutputCSV))
+	assert.Equal("name\tstatus\ttags\napi\tup\tweb, prod\ndb\tdown\t\n", testOutput(t, OutputTSV))
+}
+
+func TestOutputMarkdown(t *testing.T) {
+	assert := assert.New(t)
+
+	expected := strings.Join([]string{
+		"| name | status | tags |",
+		"| --- | --- | --- |",
+		"| api | up | web, prod |",
+		"| db | down |  |",
+		"",
+	}, "\n")
+
+	assert.Equal(expected, testOutput(t, OutputMarkdown))
+
+	var out bytes.Buffer
+
+	output, _ := OutputOf(map[string]string{"note": "a|b\nc"})
+	assert.Nil(output.Write(&out, OutputMarkdown))
+	assert.Contains(out.String(), `| a\|b<br>c |`)
+}
+
+func TestOutputUnknownFormat(t *testing.T) {
+	output, _ := OutputOf(testRecords())
+
+	err := output.Write(&bytes.Buffer{}, "jsno")
+	assert.EqualError(t, err, "Unknown output format: jsno. Did you mean: json?")
+}
+
+func TestOutputTemplate(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("api\tup\ndb\tdown\n", testOutput(t, `{{.name}}\t{{.status}}`))
+	assert.Equal("api: web, prod\ndb: \n", testOutput(t, `template:{{.name}}: {{cell .tags}}`))
+	assert.Equal("api [\"web\",\"prod\"]\ndb null\n", testOutput(t, `{{.name}} {{json .tags}}`))
+
+	// Records that render as nothing are skipped.
+	assert.Equal("api\n", testOutput(t, `{{if eq .status "up"}}{{.name}}{{end}}`))
+
+	output, _ := OutputOf(testRecords())
+
+	err := output.Write(&bytes.Buffer{}, "{{.name")
+	assert.ErrorContains(err, "Invalid output template: template: output:1: unclosed action")
+
+	err = output.Write(&bytes.Buffer{}, "{{.Name}}")
+	assert.ErrorContains(err, `map has no entry for key "Name". Fields: name, status, tags.`)
+}
+
+func TestOutputCell(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("", outputCell(nil))
+	assert.Equal("3", outputCell(3))
+	assert.Equal("a, b", outputCell([]string{"a", "b"}))
+	assert.Equal(`{"a":1}`, outputCell(map[string]int{"a": 1}))
+	assert.Equal("1s", outputCell(time.Second))
+}
+
+func TestOutputNilStringer(t *testing.T) {
+	assert := assert.New(t)
+
+	type record struct {
+		Name string     `json:"name"`
+		When *time.Time `json:"when"`
+	}
+
+	when := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	output, err := OutputOf([]record{{Name: "api"}, {Name: "db", When: &when}})
+	assert.Nil(err)
+
+	var out bytes.Buffer
+
+	assert.Nil(output.Write(&out, OutputCSV))
+	assert.Equal("name,when\napi,\ndb,2026-01-02 03:04:05 +0000 UTC\n", out.String())
+
+	out.Reset()
+	assert.Nil(output.Write(&out, OutputMarkdown))
+	assert.Contains(out.String(), "| api |  |")
+
+	out.Reset()
+	assert.Nil(output.Write(&out, OutputTable))
+	assert.Contains(out.String(), "api ")
+
+	assert.Equal("", outputCell((*time.Time)(nil)))
+}
+
+func TestAppOutput(t *testing.T) {
+	assert := assert.New(t)
+
+	var out bytes.Buffer
+
+	app := AppNew(AppFields{Name: testAppName, Out: &out, Output: true})
+
+	cmd, _ := CmdNew(CmdFields{
+		Name:    "list",
+		Columns: []string{"name", "status"},
+		ExecWithOutput: func(ctx *Context) (any, error) {
+			return testRecords(), nil
+		},
+	})
+	app.Cmds.Add(cmd)
+
+	assert.Nil(app.Run([]string{testAppName, "list"}))
+	assert.Equal("name    status\n----    ------\napi     up    \ndb      down  \n", out.String())
+
+	out.Reset()
+	assert.Nil(app.Run([]string{testAppName, "output=csv", "columns=status,tags", "list"}))
+	assert.Equal("status,tags\nup,\"web, prod\"\ndown,\n", out.String())
+
+	_, err := app.Resolve([]string{testAppName, "output=xml", "list"})
+	assert.NotNil(err)
+
+	// The command's Columns don't get in the way of a template.
+	out.Reset()
+	assert.Nil(app.Run([]string{testAppName, "output={{.name}}:{{cell .tags}}", "list"}))
+	assert.Equal("api:web, prod\ndb:\n", out.String())
+
+	err = app.Run([]string{testAppName, "columns=nope", "list"})
+	assert.ErrorContains(err, "Unknown column: nope.")
+}
+
+func TestAppOutputAfterCmd(t *testing.T) {
+	assert := assert.New(t)
+
+	var out bytes.Buffer
+
+	app := AppNew(AppFields{Name: testAppName, Out: &out, Output: true})
+
+	cmd, _ := CmdNew(CmdFields{
+		Name: "list",
+		ExecWithOutput: func(ctx *Context) (any, error) {
+			return testRecords(), nil
+		},
+	})
+	app.Cmds.Add(cmd)
+
+	assert.Nil(app.Run([]string{testAppName, "list", "output={{.name}}"}))
+	assert.Equal("api\ndb\n", out.String())
+
+	out.Reset()
+	assert.Nil(app.Run([]string{testAppName, "output=csv", "list", "columns=name"}))
+	assert.Equal("name\napi\ndb\n", out.String())
+
+	_, err := app.Resolve([]string{testAppName, "list", "output=yml"})
+	assert.EqualError(err, "Unknown output format: yml. Did you mean: yaml?")
+
+	// A command's own arg of the same name comes first.
+	own, _ := StringArgNew(ArgFields{Name: "output"})
+	cmd.Args.Add(own)
+
+	result, err := app.Resolve([]string{testAppName, "list", "output=xml"})
+	assert.Nil(err)
+	assert.Equal([]string{"xml"}, result.Args.Lookup("output").Stored())
+	assert.Equal([]string{OutputTable}, result.AppArgs.Lookup("output").Stored())
+}
+
+func TestAppOutputColor(t *testing.T) {
+	assert := assert.New(t)
+
+	var out bytes.Buffer
+
+	app := AppNew(AppFields{Name: testAppName, Out: &out, Output: true, Color: true})
+
+	cmd, _ := CmdNew(CmdFields{
+		Name:    "list",
+		Columns: []string{"name"},
+		ExecWithOutput: func(ctx *Context) (any, error) {
+			return testRecords(), nil
+		},
+	})
+	app.Cmds.Add(cmd)
+
+	assert.Nil(app.Run([]string{testAppName, "color=always", "list"}))
+	assert.Equal("\x1b[1mname\x1b[0m\n----\napi \ndb  \n", out.String())
+
+	// Only tables are colored; other formats are for programs.
+	out.Reset()
+	assert.Nil(app.Run([]string{testAppName, "color=always", "output=csv", "list"}))
+	assert.Equal("name\napi\ndb\n", out.String())
+}
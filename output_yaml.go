@@ -0,0 +1,213 @@
+package cligobrr
+
+import "encoding"
+import "fmt"
+import "io"
+import "math"
+import "reflect"
+import "strconv"
+import "strings"
+
+// writeYAML writes block style YAML: a sequence of mappings, or just
+// the one mapping for a single record. It only has to cope with what
+// OutputOf produces, which is a long way short of all of YAML.
+func writeYAML(w io.Writer, output *Output) error {
+	var records []any
+
+	for _, row := range output.Rows {
+		records = append(records, outputMap{keys: output.Columns, values: row})
+	}
+
+	var lines []string
+
+	switch {
+	case output.Single && len(records) == 1:
+		lines, _ = yamlLines(records[0])
+	case output.Single:
+		lines = []string{"null"}
+	default:
+		lines, _ = yamlLines(records)
+	}
+
+	_, err := fmt.Fprintln(w, strings.Join(lines, "\n"))
+
+	return err
+}
+
+// yamlLines returns value as unindented lines of YAML, and whether
+// they are a block collection, which has to start on a line of its
+// own rather than after a key or dash. Collections come out in block
+// style, so the caller indents them as needed.
+func yamlLines(value any) ([]string, bool) {
+	if record, ok := value.(outputMap); ok {
+		return yamlMapping(record)
+	}
+
+	rv := outputIndirect(reflect.ValueOf(value))
+	if !rv.IsValid() {
+		return []string{"null"}, false
+	}
+
+	if _, ok := rv.Interface().(encoding.TextMarshaler); ok {
+		return []string{yamlScalar(rv)}, false
+	}
+
+	switch rv.Kind() {
+	case reflect.Struct:
+		if outputScalar(rv) {
+			return []string{yamlScalar(rv)}, false
+		}
+
+		return yamlMapping(outputStruct(rv))
+	case reflect.Map:
+		if rv.Type().Key().Kind() == reflect.String {
+			return yamlMapping(outputMapOf(rv))
+		}
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8 {
+			return []string{yamlString(string(rv.Bytes()))}, false
+		}
+
+		return yamlSequence(rv)
+	}
+
+	return []string{yamlScalar(rv)}, false
+}
+
+func yamlMapping(record outputMap) ([]string, bool) {
+	if len(record.keys) == 0 {
+		return []string{"{}"}, false
+	}
+
+	var lines []string
+
+	for i, key := range record.keys {
+		key = yamlString(key)
+		value, block := yamlLines(record.values[i])
+
+		if block {
+			lines = append(lines, key+":")
+			lines = append(lines, yamlIndent(value, "  ", "  ")...)
+		} else {
+			lines = append(lines, fmt.Sprintf("%s: %s", key, value[0]))
+		}
+	}
+
+	return lines, true
+}
+
+func yamlSequence(rv reflect.Value) ([]string, bool) {
+	if rv.Len() == 0 {
+		return []string{"[]"}, false
+	}
+
+	var lines []string
+
+	for i := 0; i < rv.Len(); i++ {
+		value, _ := yamlLines(rv.Index(i).Interface())
+		lines = append(lines, yamlIndent(value, "- ", "  ")...)
+	}
+
+	return lines, true
+}
+
+func yamlIndent(lines []string, first string, rest string) []string {
+	var indented []string
+
+	for i, line := range lines {
+		if i == 0 {
+			indented = append(indented, first+line)
+		} else {
+			indented = append(indented, rest+line)
+		}
+	}
+
+	return indented
+}
+
+func yamlScalar(rv reflect.Value) string {
+	value := rv.Interface()
+
+	switch v := value.(type) {
+	case encoding.TextMarshaler:
+		text, err := v.MarshalText()
+		if err == nil {
+			return yamlString(string(text))
+		}
+	case fmt.Stringer:
+		return yamlString(v.String())
+	case error:
+		return yamlString(v.Error())
+	}
+
+	switch rv.Kind() {
+	case reflect.Bool:
+		return strconv.FormatBool(rv.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(rv.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		f := rv.Float()
+		switch {
+		case math.IsNaN(f):
+			return ".nan"
+		case math.IsInf(f, 1):
+			return ".inf"
+		case math.IsInf(f, -1):
+			return "-.inf"
+		}
+
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	case reflect.String:
+		return yamlString(rv.String())
+	}
+
+	return yamlString(fmt.Sprint(value))
+}
+
+// yamlString leaves s as it is when it can't be mistaken for anything
+// else, and double quotes it otherwise.
+func yamlString(s string) string {
+	if yamlPlain(s) {
+		return s
+	}
+
+	return strconv.Quote(s)
+}
+
+func yamlPlain(s string) bool {
+	if len(s) == 0 || s != strings.TrimSpace(s) {
+		return false
+	}
+
+	// Indicators that mean something at the start of a scalar.
+	if strings.ContainsAny(s[:1], "-?:,[]{}#&*!|>'\"%@`") {
+		return false
+	}
+
+	if strings.Contains(s, ": ") || strings.Contains(s, " #") || strings.HasSuffix(s, ":") {
+		return false
+	}
+
+	for _, r := range s {
+		if r < ' ' || r == 0x7f || r == '\ufeff' {
+			return false
+		}
+	}
+
+	switch strings.ToLower(s) {
+	case "~", "null", "true", "false", "yes", "no", "on", "off", "y", "n":
+		return false
+	}
+
+	// Anything that looks like a number would be read back as one.
+	_, err := strconv.ParseFloat(strings.ReplaceAll(s, "_", ""), 64)
+	if err == nil {
+		return false
+	}
+
+	_, err = strconv.ParseInt(s, 0, 64)
+
+	return err != nil && !strings.HasPrefix(strings.ToLower(s), ".inf") && !strings.EqualFold(s, ".nan")
+}
@@ -0,0 +1,99 @@
+package cligobrr
+
+import "bytes"
+import "math"
+import "strings"
+import "testing"
+import "time"
+import "github.com/stretchr/testify/assert"
+
+func TestOutputYAML(t *testing.T) {
+	assert := assert.New(t)
+
+	expected := strings.Join([]string{
+		"- name: api",
+		"  status: up",
+		"  tags:",
+		"    - web",
+		"    - prod",
+		"- name: db",
+		"  status: down",
+		"  tags: []",
+		"",
+	}, "\n")
+
+	assert.Equal(expected, testOutput(t, OutputYAML))
+}
+
+func TestOutputYAMLSingle(t *testing.T) {
+	assert := assert.New(t)
+
+	type nested struct {
+		Region string         `json:"region"`
+		Limits map[string]int `json:"limits"`
+		Zones  []nested       `json:"zones,omitempty"`
+	}
+
+	record := nested{
+		Region: "eu",
+		Limits: map[string]int{"memory": 512, "cpu": 2},
+		Zones:  []nested{{Region: "eu-1"}},
+	}
+
+	var out bytes.Buffer
+
+	output, _ := OutputOf(record)
+	assert.Nil(output.Write(&out, OutputYAML))
+
+	expected := strings.Join([]string{
+		"region: eu",
+		"limits:",
+		"  cpu: 2",
+		"  memory: 512",
+		"zones:",
+		"  - region: eu-1",
+		"    limits: {}",
+		"    zones: []",
+		"",
+	}, "\n")
+
+	assert.Equal(expected, out.String())
+
+	out.Reset()
+	output, _ = OutputOf([]int{})
+	assert.Nil(output.Write(&out, OutputYAML))
+	assert.Equal("[]\n", out.String())
+}
+
+func TestYAMLScalars(t *testing.T) {
+	assert := assert.New(t)
+
+	scalar := func(value any) string {
+		lines, block := yamlLines(value)
+		assert.False(block)
+		return strings.Join(lines, "\n")
+	}
+
+	assert.Equal("null", scalar(nil))
+	assert.Equal("true", scalar(true))
+	assert.Equal("-3", scalar(-3))
+	assert.Equal("1.5", scalar(1.5))
+	assert.Equal(".inf", scalar(math.Inf(1)))
+	assert.Equal("hello world", scalar("hello world"))
+	assert.Equal("2026-01-02T03:04:05Z", scalar(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)))
+	assert.Equal("1m0s", scalar(time.Minute))
+}
+
+func TestYAMLString(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, plain := range []string{"api", "hello world", "a-b", "日本語", "v1.2.3", "a:b"} {
+		assert.Equal(plain, yamlString(plain), plain)
+	}
+
+	for _, quoted := range []string{"", " lead", "trail ", "- item", "key: value", "a #b", "true", "No", "null", "~", "42", "1e3", "0x1f", "line\nbreak", "[x]", "*ref", "end:"} {
+		assert.True(strings.HasPrefix(yamlString(quoted), `"`), quoted)
+	}
+
+	assert.Equal(`"line\nbreak"`, yamlString("line\nbreak"))
+}
@@ -0,0 +1,294 @@
+package cligobrr
+
+import "io"
+import "os"
+import "slices"
+import "strings"
+
+// Action describes what the caller is expected to do
+// with a Result once the input has been resolved.
+type Action int
+
+const (
+	// ActionRun means Cmd should be executed.
+	ActionRun Action = iota
+
+	// ActionHelp means help should be shown, either for HelpFor
+	// or, when HelpFor is nil, for the app itself.
+	ActionHelp
+
+	// ActionVersion means the app version should be shown.
+	ActionVersion
+
+	// ActionComplete means the completions for Complete should be
+	// written, for a shell's completion script to read.
+	ActionComplete
+)
+
+// Result is everything Resolve learned from the input. Nothing is
+// printed while resolving; call Render to show help or the version
+// when the Action calls for it.
+//
+// AppArgs and Args hold the parsed values for the app and for Cmd.
+// They are copies made for this Result alone, so the definitions are
+// never modified and an App can be resolved over and over, from as
+// many goroutines as needed.
+type Result struct {
+	Action   Action
+	Cmd      *Cmd
+	HelpFor  *Cmd
+	Help     []string
+	AppArgs  Args
+	Args     Args
+	Warnings []Warning
+	Complete []string
+	app      *App
+
+	// appInput is what was typed for the app, which can come
+	// after the command too for global args.
+	appInput []string
+}
+
+// Render displays whatever the Action calls for on the app's Out.
+// There is nothing to render for ActionRun; see Exec for that.
+func (self *Result) Render() error {
+	return self.RenderTo(self.out())
+}
+
+// RenderTo is Render, but to any writer, which is handy for things
+// like sending help to stderr when something has gone wrong.
+func (self *Result) RenderTo(out io.Writer) error {
+	switch self.Action {
+	case ActionHelp:
+		if self.HelpFor != nil {
+			return cmdHelp(out, self.HelpFor, self.Help, self.palette(out))
+		}
+
+		if self.app != nil {
+			return appHelp(out, self.app, self.Help, self.palette(out))
+		}
+	case ActionVersion:
+		if self.app != nil {
+			self.app.version(out)
+		}
+	case ActionComplete:
+		if self.app != nil {
+			return writeCompletions(out, self.app, self.Complete)
+		}
+	}
+
+	return nil
+}
+
+// Exec runs the resolved command. When the result came from an
+// App, its writers and reader are handed to the command.
+func (self *Result) Exec() error {
+	if self.Action != ActionRun || self.Cmd == nil {
+		return nil
+	}
+
+	ctx := Context{
+		App: self.app,
+		Cmd: self.Cmd,
+		Out: self.out(),
+		Err: os.Stderr,
+		In:  os.Stdin,
+	}
+
+	if self.app != nil {
+		ctx.Err = self.app.Err
+		ctx.In = self.app.In
+	}
+
+	ctx.AppArgs = self.AppArgs
+	ctx.Args = self.Args
+
+	return self.Cmd.exec(&ctx)
+}
+
+// palette is how whatever is written to out gets colored, going by
+// the color arg and the app's Styles.
+func (self *Result) palette(out io.Writer) palette {
+	var styles map[Style]string
+	if self.app != nil {
+		styles = self.app.Styles
+	}
+
+	return paletteFor(out, colorMode(&self.AppArgs), styles)
+}
+
+func (self *Result) out() io.Writer {
+	if self.app != nil && self.app.Out != nil {
+		return self.app.Out
+	}
+
+	return os.Stdout
+}
+
+// help records that help was asked for. The input is followed down
+// through subcommands first, so `help deploy status` is about the
+// status command, and whatever is left over must be an arg of the
+// command that was reached. Anything asked about has to exist so
+// that rendering can't fail later on.
+func (self *Result) help(helpFor *Cmd, input []string) error {
+	for len(input) > 0 {
+		cmd := self.cmdsOf(helpFor).get(input[0])
+		if cmd == nil || cmd.builtin {
+			break
+		}
+
+		helpFor = cmd
+		input = input[1:]
+	}
+
+	arguments := self.argsOf(helpFor)
+
+	_, _, err := helpTopic(arguments, input)
+	if err != nil {
+		// If it wasn't an arg but looks like a command,
+		// that is the more helpful thing to point out.
+		token := input[0]
+		if token == helpAll && len(input) > 1 {
+			token = input[1]
+		}
+
+		// The built-in commands are left out already, and so is
+		// the token itself, so nothing is offered as a typo of
+		// what was just typed.
+		candidates := slices.DeleteFunc(self.cmdsOf(helpFor).identifiers(), func(candidate string) bool {
+			return candidate == token
+		})
+
+		suggestions := self.app.suggest(token, candidates)
+		if len(suggestions) > 0 {
+			return errUnexpectedCmd(token, suggestions)
+		}
+
+		return err
+	}
+
+	self.Action = ActionHelp
+	self.HelpFor = helpFor
+	self.Help = input
+
+	return nil
+}
+
+// helpFlag looks for --help or -h anywhere in the input. If there is
+// one, the commands in the input are followed as far as they go and
+// help is shown for wherever that ends up. Args aren't parsed at all,
+// since they are quite likely incomplete.
+func (self *Result) helpFlag(helpFor *Cmd, input []string) bool {
+	if !slices.ContainsFunc(input, isHelpFlag) {
+		return false
+	}
+
+	for _, token := range input {
+		if isHelpFlag(token) || strings.Contains(token, "=") {
+			continue
+		}
+
+		cmd := self.cmdsOf(helpFor).get(token)
+		if cmd == nil || cmd.builtin {
+			break
+		}
+
+		helpFor = cmd
+	}
+
+	self.Action = ActionHelp
+	self.HelpFor = helpFor
+	self.Cmd = self.cmdsOf(helpFor).get("help")
+
+	return true
+}
+
+func isHelpFlag(token string) bool {
+	return token == helpFlagLong || token == helpFlagShort
+}
+
+// cmdsOf and argsOf treat a nil command as the app itself.
+func (self *Result) cmdsOf(cmd *Cmd) *Cmds {
+	if cmd == nil {
+		return &self.app.Cmds
+	}
+
+	return &cmd.Cmds
+}
+
+func (self *Result) argsOf(cmd *Cmd) Args {
+	if cmd == nil {
+		return self.app.Args
+	}
+
+	return cmd.Args
+}
+
+// parseAppInput parses whatever was typed for the app, now that all
+// of it has been found.
+func (self *Result) parseAppInput() error {
+	if len(self.appInput) == 0 || self.app == nil {
+		return nil
+	}
+
+	self.AppArgs = self.app.Args.clone()
+
+	return self.AppArgs.parse(self.appInput, self)
+}
+
+// unparsed fills in args for anything that never had input to parse,
+// so that the environment and defaults are still available through
+// the accessors.
+func (self *Result) unparsed() error {
+	if len(self.AppArgs.args) == 0 && self.app != nil {
+		self.AppArgs = self.app.Args.clone()
+
+		err := self.AppArgs.storeEnv()
+		if err != nil {
+			return err
+		}
+
+		self.AppArgs.storeDefaults()
+
+		// The environment can hold anything, so it has to
+		// be one of the choices just as typed input would.
+		err = self.AppArgs.validateChoices()
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(self.Args.args) == 0 && self.Cmd != nil {
+		self.Args = self.Cmd.Args.clone()
+
+		err := self.Args.storeEnv()
+		if err != nil {
+			return err
+		}
+
+		self.Args.storeDefaults()
+
+		// The environment can hold anything, so it has to
+		// be one of the choices just as typed input would.
+		err = self.Args.validateChoices()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (self *Result) deprecated(kind string, name string, deprecation *Deprecation) {
+	if deprecation == nil {
+		return
+	}
+
+	warning := Warning{
+		Deprecation: *deprecation,
+		Kind:        kind,
+		Name:        name,
+	}
+
+	self.Warnings = append(self.Warnings, warning)
+}
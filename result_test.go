@@ -0,0 +1,311 @@
+package cligobrr
+
+import "fmt"
+import "io"
+import "sync"
+import "testing"
+import "github.com/stretchr/testify/assert"
+
+func testResolveApp(t *testing.T) *App {
+	app := AppNew(AppFields{Name: testAppName, Version: testAppVersion})
+
+	arg, err := StringArgNew(ArgFields{Name: testArgName})
+	assert.Nil(t, err)
+
+	app.Args.Add(arg)
+
+	cmd, err := CmdNew(CmdFields{Name: testCmdName, Exec: testCmdExec})
+	assert.Nil(t, err)
+
+	cmdArg, err := IntArgNew(ArgFields{Name: "count"})
+	assert.Nil(t, err)
+
+	cmd.Args.Add(cmdArg)
+	app.Cmds.Add(cmd)
+
+	return app
+}
+
+func TestAppResolveNoInput(t *testing.T) {
+	assert := assert.New(t)
+
+	app := testResolveApp(t)
+
+	result, err := app.Resolve([]string{testAppName})
+	assert.Nil(err)
+	assert.Equal(ActionHelp, result.Action)
+	assert.Nil(result.HelpFor)
+	assert.Equal("help", result.Cmd.Name)
+}
+
+func TestAppResolveHelp(t *testing.T) {
+	assert := assert.New(t)
+
+	app := testResolveApp(t)
+
+	result, err := app.Resolve([]string{testAppName, "help", testArgName})
+	assert.Nil(err)
+	assert.Equal(ActionHelp, result.Action)
+	assert.Nil(result.HelpFor)
+	assert.Equal([]string{testArgName}, result.Help)
+
+	// Asking about something that doesn't exist fails up front,
+	// rather than when rendering.
+	result, err = app.Resolve([]string{testAppName, "help", "nope"})
+	assert.Nil(result)
+	assert.NotNil(err)
+
+	// Help about help isn't a typo of anything.
+	result, err = app.Resolve([]string{testAppName, "help", "help"})
+	assert.Nil(result)
+	assert.EqualError(err, "Unexpected argument: help.")
+
+	result, err = app.Resolve([]string{testAppName, testCmdName, "help", "help"})
+	assert.Nil(result)
+	assert.EqualError(err, "Unexpected argument: help.")
+}
+
+func TestAppResolveCmdHelp(t *testing.T) {
+	assert := assert.New(t)
+
+	app := testResolveApp(t)
+
+	result, err := app.Resolve([]string{testAppName, testCmdName, "help", "count"})
+	assert.Nil(err)
+	assert.Equal(ActionHelp, result.Action)
+	assert.Equal(testCmdName, result.HelpFor.Name)
+	assert.Equal([]string{"count"}, result.Help)
+	assert.Equal("help", result.Cmd.Name)
+}
+
+func TestAppResolveVersion(t *testing.T) {
+	assert := assert.New(t)
+
+	app := testResolveApp(t)
+
+	result, err := app.Resolve([]string{testAppName, "version"})
+	assert.Nil(err)
+	assert.Equal(ActionVersion, result.Action)
+	assert.Equal("version", result.Cmd.Name)
+}
+
+func TestAppResolveRun(t *testing.T) {
+	assert := assert.New(t)
+
+	app := testResolveApp(t)
+
+	result, err := app.Resolve([]string{testAppName, testCmdName, "count=3"})
+	assert.Nil(err)
+	assert.Equal(ActionRun, result.Action)
+	assert.Equal(testCmdName, result.Cmd.Name)
+	assert.Nil(result.Render())
+}
+
+func TestCmdResolveHelpWithoutExec(t *testing.T) {
+	assert := assert.New(t)
+
+	cmd, err := CmdNew(CmdFields{Name: testCmdName})
+	assert.Nil(err)
+
+	result, err := cmd.Resolve([]string{})
+	assert.Nil(err)
+	assert.Equal(ActionHelp, result.Action)
+	assert.Equal(testCmdName, result.HelpFor.Name)
+}
+
+func TestAppResolveLeavesDefinitionsAlone(t *testing.T) {
+	assert := assert.New(t)
+
+	app := testResolveApp(t)
+
+	result, err := app.Resolve([]string{testAppName, "myArg=val", testCmdName, "count=3"})
+	assert.Nil(err)
+
+	appVal, err := result.AppArgs.AsString(testArgName)
+	assert.Nil(err)
+	assert.Equal("val", appVal)
+
+	count, err := result.Args.AsInt("count")
+	assert.Nil(err)
+	assert.Equal(int64(3), count)
+
+	// Nothing was stored on the definitions.
+	assert.Empty((*app.Args.get(testArgName)).Stored())
+	assert.Empty((*app.Cmds.get(testCmdName).Args.get("count")).Stored())
+}
+
+func TestAppResolveRepeatedly(t *testing.T) {
+	assert := assert.New(t)
+
+	app := AppNew(AppFields{Name: testAppName})
+
+	cmd, err := CmdNew(CmdFields{Name: testCmdName, Exec: testCmdExec})
+	assert.Nil(err)
+
+	arg, err := StringArgNew(ArgFields{Name: "name", Required: true})
+	assert.Nil(err)
+
+	cmd.Args.Add(arg)
+	app.Cmds.Add(cmd)
+
+	result, err := app.Resolve([]string{testAppName, testCmdName, "name=first"})
+	assert.Nil(err)
+
+	// The value from the first resolve must not satisfy the
+	// required check on the second.
+	_, err = app.Resolve([]string{testAppName, testCmdName})
+	assert.NotNil(err)
+
+	// And the first result is unaffected by anything since.
+	name, err := result.Args.AsString("name")
+	assert.Nil(err)
+	assert.Equal("first", name)
+}
+
+func TestAppResolveConcurrently(t *testing.T) {
+	assert := assert.New(t)
+
+	app := testResolveApp(t)
+
+	var wg sync.WaitGroup
+	counts := make([]int64, 50)
+
+	for i := range counts {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			input := []string{testAppName, testCmdName, fmt.Sprintf("count=%d", i)}
+			result, err := app.Resolve(input)
+			if err == nil {
+				counts[i], _ = result.Args.AsInt("count")
+			}
+		}(i)
+	}
+
+	wg.Wait()
+
+	for i, count := range counts {
+		assert.Equal(int64(i), count)
+	}
+}
+
+func TestAppResolveDefaults(t *testing.T) {
+	assert := assert.New(t)
+
+	app := AppNew(AppFields{Name: testAppName})
+
+	appArg, err := StringArgNew(ArgFields{Name: "profile", Default: "dev"})
+	assert.Nil(err)
+
+	app.Args.Add(appArg)
+
+	cmd, err := CmdNew(CmdFields{Name: testCmdName, Exec: testCmdExec, Default: true})
+	assert.Nil(err)
+
+	arg, err := IntArgNew(ArgFields{Name: "count", Default: "1"})
+	assert.Nil(err)
+
+	cmd.Args.Add(arg)
+	app.Cmds.Add(cmd)
+
+	result, err := app.Resolve([]string{testAppName})
+	assert.Nil(err)
+	assert.Equal(testCmdName, result.Cmd.Name)
+
+	profile, err := result.AppArgs.AsString("profile")
+	assert.Nil(err)
+	assert.Equal("dev", profile)
+
+	count, err := result.Args.AsInt("count")
+	assert.Nil(err)
+	assert.Equal(int64(1), count)
+}
+
+func testHelpTree(t *testing.T) *App {
+	app := AppNew(AppFields{Name: testAppName, Out: io.Discard})
+
+	deploy, err := CmdNew(CmdFields{Name: "deploy", Alias: "d"})
+	assert.Nil(t, err)
+
+	region, err := StringArgNew(ArgFields{Name: "region", Required: true})
+	assert.Nil(t, err)
+
+	status, err := CmdNew(CmdFields{Name: "status", Exec: testCmdExec})
+	assert.Nil(t, err)
+
+	verbose, err := BoolArgNew(ArgFields{Name: "verbose"})
+	assert.Nil(t, err)
+
+	deploy.Args.Add(region)
+	status.Args.Add(verbose)
+	deploy.Cmds.Add(status)
+	app.Cmds.Add(deploy)
+
+	return app
+}
+
+func TestAppResolveNestedHelp(t *testing.T) {
+	assert := assert.New(t)
+
+	app := testHelpTree(t)
+	deploy := app.Cmds.Lookup("deploy")
+	status := app.Cmds.LookupPath("deploy", "status")
+
+	result, err := app.Resolve([]string{testAppName, "help", "deploy"})
+	assert.Nil(err)
+	assert.Equal(ActionHelp, result.Action)
+	assert.Same(deploy, result.HelpFor)
+	assert.Empty(result.Help)
+
+	result, err = app.Resolve([]string{testAppName, "help", "d", "status"})
+	assert.Nil(err)
+	assert.Same(status, result.HelpFor)
+
+	result, err = app.Resolve([]string{testAppName, "help", "deploy", "region"})
+	assert.Nil(err)
+	assert.Same(deploy, result.HelpFor)
+	assert.Equal([]string{"region"}, result.Help)
+
+	result, err = app.Resolve([]string{testAppName, "deploy", "help", "status", "verbose"})
+	assert.Nil(err)
+	assert.Same(status, result.HelpFor)
+	assert.Equal([]string{"verbose"}, result.Help)
+
+	_, err = app.Resolve([]string{testAppName, "help", "deploy", "stauts"})
+	assert.EqualError(err, "Unexpected command: stauts. Did you mean: status?")
+
+	_, err = app.Resolve([]string{testAppName, "help", "deploy", "regoin"})
+	assert.EqualError(err, "Unexpected argument: regoin. Did you mean: region?")
+}
+
+func TestAppResolveHelpFlag(t *testing.T) {
+	assert := assert.New(t)
+
+	app := testHelpTree(t)
+	deploy := app.Cmds.Lookup("deploy")
+	status := app.Cmds.LookupPath("deploy", "status")
+
+	// The required region is missing, but that doesn't matter
+	// when all that is wanted is help.
+	result, err := app.Resolve([]string{testAppName, "deploy", "--help"})
+	assert.Nil(err)
+	assert.Equal(ActionHelp, result.Action)
+	assert.Same(deploy, result.HelpFor)
+	assert.Equal("help", result.Cmd.Name)
+
+	result, err = app.Resolve([]string{testAppName, "-h", "deploy", "region=eu", "status", "verbose=nope"})
+	assert.Nil(err)
+	assert.Same(status, result.HelpFor)
+
+	result, err = app.Resolve([]string{testAppName, "--help"})
+	assert.Nil(err)
+	assert.Equal(ActionHelp, result.Action)
+	assert.Nil(result.HelpFor)
+
+	result, err = deploy.Resolve([]string{"status", "-h"})
+	assert.Nil(err)
+	assert.Same(status, result.HelpFor)
+	assert.Nil(result.Render())
+}
@@ -0,0 +1,104 @@
+package cligobrr
+
+import "slices"
+import "strings"
+import "unicode/utf8"
+
+// suggest returns the candidates closest to token, using the app's
+// SuggestDistance. There needn't be an app, in which case it's the
+// default distance.
+func (self *App) suggest(token string, candidates []string) []string {
+	distance := suggestDistanceDefault
+	if self != nil && self.SuggestDistance != 0 {
+		distance = self.SuggestDistance
+	}
+
+	return suggest(token, candidates, distance)
+}
+
+// suggest returns the candidates closest to token, provided they are
+// within reach. Ties are all returned, in candidate order. A token
+// shorter than suggestMinLength is too short to guess at, and a longer
+// one can be an edit off for every suggestMinLength runes it has, up to
+// distance. Otherwise short tokens are close to far too much: x is two
+// edits from anything of one or two letters.
+func suggest(token string, candidates []string, distance int) []string {
+	token = strings.ToLower(strings.TrimSpace(token))
+
+	length := utf8.RuneCountInString(token)
+	if length < suggestMinLength {
+		return nil
+	}
+
+	distance = min(distance, length/suggestMinLength)
+	if distance <= 0 {
+		return nil
+	}
+
+	best := distance + 1
+	var matches []string
+
+	for _, candidate := range candidates {
+		if len(candidate) == 0 || slices.Contains(matches, candidate) {
+			continue
+		}
+
+		edits := editDistance(token, strings.ToLower(candidate))
+		if edits > distance {
+			continue
+		}
+
+		if edits < best {
+			best = edits
+			matches = []string{candidate}
+		} else if edits == best {
+			matches = append(matches, candidate)
+		}
+	}
+
+	return matches
+}
+
+// editDistance is the optimal string alignment distance between a and b:
+// the number of insertions, deletions, substitutions and transpositions of
+// adjacent characters needed to turn one into the other. Transpositions
+// matter here because "delpoy" should be one edit away from "deploy".
+func editDistance(a, b string) int {
+	ra := []rune(a)
+	rb := []rune(b)
+
+	// Three rows are all we ever need: the current one, the previous
+	// one, and the one before that for transpositions.
+	prev2 := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			curr[j] = min(
+				prev[j]+1,
+				curr[j-1]+1,
+				prev[j-1]+cost,
+			)
+
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				curr[j] = min(curr[j], prev2[j-2]+1)
+			}
+		}
+
+		prev2, prev, curr = prev, curr, prev2
+	}
+
+	return prev[len(rb)]
+}
@@ -0,0 +1,92 @@
+package cligobrr
+
+import "testing"
+import "github.com/stretchr/testify/assert"
+
+func TestEditDistance(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(0, editDistance("deploy", "deploy"))
+	assert.Equal(1, editDistance("delpoy", "deploy"))
+	assert.Equal(1, editDistance("deploy", "deploys"))
+	assert.Equal(1, editDistance("deploy", "depoy"))
+	assert.Equal(1, editDistance("deploy", "deplay"))
+	assert.Equal(6, editDistance("", "deploy"))
+	assert.Equal(3, editDistance("kitten", "sitting"))
+}
+
+func TestSuggest(t *testing.T) {
+	assert := assert.New(t)
+
+	candidates := []string{"deploy", "delete", "status", "help"}
+
+	assert.Equal([]string{"deploy"}, suggest("delpoy", candidates, suggestDistanceDefault))
+	assert.Equal([]string{"status"}, suggest("STATSU", candidates, suggestDistanceDefault))
+	assert.Empty(suggest("nothing-like-it", candidates, suggestDistanceDefault))
+	assert.Empty(suggest("", candidates, suggestDistanceDefault))
+}
+
+func TestSuggestTies(t *testing.T) {
+	assert := assert.New(t)
+
+	candidates := []string{"Q11", "Q12", "Q13", "Q14"}
+	assert.Equal(candidates, suggest("Q15", candidates, suggestDistanceDefault))
+}
+
+func TestSuggestShortTokens(t *testing.T) {
+	assert := assert.New(t)
+
+	candidates := []string{"d", "id", "add", "help", "deploy"}
+
+	// Too short to be taken for anything.
+	assert.Empty(suggest("x", candidates, suggestDistanceDefault))
+	assert.Empty(suggest("ad", candidates, suggestDistanceDefault))
+
+	// Short ones get a single edit, longer ones more.
+	assert.Equal([]string{"add"}, suggest("adx", candidates, suggestDistanceDefault))
+	assert.Empty(suggest("hxlx", candidates, suggestDistanceDefault))
+	assert.Equal([]string{"deploy"}, suggest("dxplxy", candidates, suggestDistanceDefault))
+}
+
+func TestSuggestDistance(t *testing.T) {
+	assert := assert.New(t)
+
+	candidates := []string{"deploy"}
+
+	assert.Empty(suggest("delpoy", candidates, 0))
+	assert.Empty(suggest("delpoy", candidates, -1))
+
+	assert.Equal([]string{"deploy"}, suggest("delpoy", candidates, 1))
+	assert.Empty(suggest("dpeloi", candidates, 1))
+
+	// However far it's allowed to go, a token only
+	// goes as far as its length lets it.
+	assert.Equal([]string{"deploy"}, suggest("dpeloi", candidates, 3))
+	assert.Empty(suggest("dpelo", candidates, 3))
+}
+
+func TestAppSuggestDistance(t *testing.T) {
+	assert := assert.New(t)
+
+	app := AppNew(AppFields{Name: testAppName})
+
+	cmd, err := CmdNew(CmdFields{Name: "deploy", Exec: testCmdExec})
+	assert.Nil(err)
+	app.Cmds.Add(cmd)
+
+	_, err = app.Resolve([]string{testAppName, "dpelyo"})
+	assert.EqualError(err, "Unexpected command: dpelyo. Did you mean: deploy?")
+
+	app.SuggestDistance = 1
+	_, err = app.Resolve([]string{testAppName, "dpelyo"})
+	assert.EqualError(err, "Unexpected command: dpelyo.")
+
+	app.SuggestDistance = -1
+	_, err = app.Resolve([]string{testAppName, "delpoy"})
+	assert.EqualError(err, "Unexpected command: delpoy.")
+
+	// Help gets no closer to a guess for one letter.
+	app.SuggestDistance = 0
+	_, err = app.Resolve([]string{testAppName, "help", "all", "x"})
+	assert.EqualError(err, "Unexpected argument: x.")
+}
@@ -1,20 +1,77 @@
 package cligobrr
 
 import "fmt"
+import "io"
+import "slices"
 import "strings"
 
+// Align is how a column's cells are lined up within it.
+type Align int
+
+const (
+	AlignLeft Align = iota
+	AlignRight
+	AlignCenter
+)
+
+// Border is the style of line drawn around and between cells.
+type Border int
+
+const (
+	// BorderNone separates columns with Pad spaces and underlines
+	// the headers, if there are any.
+	BorderNone Border = iota
+
+	// BorderASCII draws lines with +, - and |.
+	BorderASCII
+
+	// BorderBox draws lines with box-drawing characters.
+	BorderBox
+)
+
 type TableFields struct {
 	Cols uint8
 	Pad  uint8
+
+	// Headers, when given, are shown above the rows and
+	// set apart from them.
+	Headers []string
+
+	// Align is the alignment of each column. Columns
+	// without one are aligned left.
+	Align []Align
+
+	// Border is drawn around and between cells. With a border, Pad
+	// is ignored and cells get a single space on either side.
+	Border Border
+
+	// MaxWidths caps how wide each column can get, with 0 for no
+	// limit. Cells that are too wide are cut short with an ellipsis.
+	MaxWidths []int
+
+	// Width is as wide as the table is allowed to get, or 0 for no
+	// limit. When a table is too wide, the Wrap columns (the last one,
+	// unless others are given) are narrowed and their cells wrapped
+	// onto extra lines.
+	Width int
+	Wrap  []int
+
+	// Paint, when given, can dress up each cell, e.g. in color. It's
+	// called once the table is laid out, so escape sequences are never
+	// measured or cut in two. Row is the index of the row as it was
+	// added, for every line of a wrapped row, or -1 for the headers.
+	Paint func(row int, col int, cell string) string
 }
 
 type Table struct {
 	TableFields
-	lens []uint8
-	rows [][]string
+	lens    []int
+	header  []string
+	rows    [][]string
+	sources []int
 }
 
-func tableNew(fields TableFields) (*Table, error) {
+func TableNew(fields TableFields) (*Table, error) {
 	if fields.Cols == 0 {
 		return nil, errTableColsRequired()
 	}
@@ -25,7 +82,16 @@ func tableNew(fields TableFields) (*Table, error) {
 
 	table := Table{
 		TableFields: fields,
-		lens:        make([]uint8, fields.Cols, fields.Cols),
+		lens:        make([]int, fields.Cols, fields.Cols),
+	}
+
+	if len(fields.Headers) > 0 {
+		if len(fields.Headers) != int(fields.Cols) {
+			return nil, errTableRowIncorrectCols(fields.Cols)
+		}
+
+		table.header = slices.Clone(fields.Headers)
+		table.measure(table.header)
 	}
 
 	return &table, nil
@@ -37,41 +103,384 @@ func (self *Table) Add(row []string) error {
 		return errTableRowIncorrectCols(self.Cols)
 	}
 
+	row = slices.Clone(row)
+	self.measure(row)
+	self.rows = append(self.rows, row)
+
+	return nil
+}
+
+func (self *Table) measure(row []string) {
 	for i, val := range row {
-		valLen := uint8(len(val))
+		valLen := displayWidth(val)
 		if valLen > self.lens[i] {
 			self.lens[i] = valLen
 		}
 	}
-
-	self.rows = append(self.rows, row)
-
-	return nil
 }
 
 func (self *Table) normalize() {
-	for _, row := range self.rows {
+	for _, row := range self.all() {
 		for i, cell := range row {
-			cellLen := uint8(len(cell))
-			padLen := self.lens[i] - cellLen
-			if padLen > 0 {
-				pad := strings.Repeat(" ", int(padLen))
-				row[i] = fmt.Sprintf("%s%s", cell, pad)
-			}
+			row[i] = self.align(i, cell, self.lens[i])
 		}
 	}
 }
 
+// all returns the header, if there is one, followed by the rows.
+func (self *Table) all() [][]string {
+	if self.header == nil {
+		return self.rows
+	}
+
+	return append([][]string{self.header}, self.rows...)
+}
+
+// align pads cell out to width, on whichever
+// side(s) column i is aligned to.
+func (self *Table) align(i int, cell string, width int) string {
+	padLen := width - displayWidth(cell)
+	if padLen <= 0 {
+		return cell
+	}
+
+	align := AlignLeft
+	if i < len(self.Align) {
+		align = self.Align[i]
+	}
+
+	switch align {
+	case AlignRight:
+		return fmt.Sprintf("%s%s", strings.Repeat(" ", padLen), cell)
+	case AlignCenter:
+		left := padLen / 2
+		return fmt.Sprintf("%s%s%s", strings.Repeat(" ", left), cell, strings.Repeat(" ", padLen-left))
+	}
+
+	return fmt.Sprintf("%s%s", cell, strings.Repeat(" ", padLen))
+}
+
 func (self *Table) ToString() string {
 	var output []string
 
-	self.normalize()
+	table := self.layout()
+	style := tableBorders[table.Border]
 
-	padding := strings.Repeat(" ", int(self.Pad))
+	if table.Border == BorderNone {
+		padding := strings.Repeat(" ", int(table.Pad))
+
+		if table.header != nil {
+			output = append(output, strings.Join(table.header, padding))
+			output = append(output, strings.Join(table.underlines(), padding))
+		}
 
-	for _, row := range self.rows {
-		output = append(output, strings.Join(row, padding))
+		for _, row := range table.rows {
+			output = append(output, strings.Join(row, padding))
+		}
+
+		return strings.Join(output, "\n")
+	}
+
+	output = append(output, table.rule(style.top))
+
+	if table.header != nil {
+		output = append(output, table.line(style, table.header))
+		output = append(output, table.rule(style.middle))
 	}
 
+	for _, row := range table.rows {
+		output = append(output, table.line(style, row))
+	}
+
+	output = append(output, table.rule(style.bottom))
+
 	return strings.Join(output, "\n")
 }
+
+// layout truncates, wraps, paints and pads a copy of the table, so
+// that the cells as they were added are left alone and the table can
+// be rendered again, or added to, as many times as needed.
+func (self *Table) layout() *Table {
+	table := *self
+	table.lens = slices.Clone(self.lens)
+	table.header = slices.Clone(self.header)
+	table.rows = make([][]string, len(self.rows))
+
+	for i, row := range self.rows {
+		table.rows[i] = slices.Clone(row)
+	}
+
+	table.truncate()
+	table.wrap()
+	table.paint()
+	table.normalize()
+
+	return &table
+}
+
+// WriteTo writes the table, followed by a newline, to w.
+func (self *Table) WriteTo(w io.Writer) (int64, error) {
+	n, err := fmt.Fprintln(w, self.ToString())
+	return int64(n), err
+}
+
+// underlines sets the headers apart when there is no border to do
+// it, each underline as long as the header above it.
+func (self *Table) underlines() []string {
+	var underlines []string
+
+	for i, header := range self.header {
+		underline := strings.Repeat("-", displayWidth(strings.TrimSpace(header)))
+		underlines = append(underlines, self.align(i, underline, self.lens[i]))
+	}
+
+	return underlines
+}
+
+type tableBorder struct {
+	vertical string
+	top      [4]string
+	middle   [4]string
+	bottom   [4]string
+}
+
+// tableBorders has, for each border, the vertical line and then the
+// horizontal line and the left, inner and right joins for the line
+// across the top, the one under the headers, and the one at the bottom.
+var tableBorders = map[Border]tableBorder{
+	BorderNone: {},
+	BorderASCII: {
+		vertical: "|",
+		top:      [4]string{"-", "+", "+", "+"},
+		middle:   [4]string{"-", "+", "+", "+"},
+		bottom:   [4]string{"-", "+", "+", "+"},
+	},
+	BorderBox: {
+		vertical: "│",
+		top:      [4]string{"─", "┌", "┬", "┐"},
+		middle:   [4]string{"─", "├", "┼", "┤"},
+		bottom:   [4]string{"─", "└", "┴", "┘"},
+	},
+}
+
+func (self *Table) line(style tableBorder, row []string) string {
+	vertical := style.vertical
+	inner := fmt.Sprintf(" %s ", vertical)
+
+	return fmt.Sprintf("%s %s %s", vertical, strings.Join(row, inner), vertical)
+}
+
+func (self *Table) rule(chars [4]string) string {
+	var segments []string
+
+	for _, width := range self.lens {
+		segments = append(segments, strings.Repeat(chars[0], width+2))
+	}
+
+	return chars[1] + strings.Join(segments, chars[2]) + chars[3]
+}
+
+// truncate cuts short any cells wider than their column's MaxWidths.
+func (self *Table) truncate() {
+	for i, max := range self.MaxWidths {
+		if i >= len(self.lens) || max <= 0 || self.lens[i] <= max {
+			continue
+		}
+
+		for _, row := range self.all() {
+			row[i] = truncateWidth(row[i], max)
+		}
+
+		self.lens[i] = max
+	}
+}
+
+// wrap narrows the Wrap columns until the table fits in Width and
+// spreads any cells that no longer fit over extra rows, leaving the
+// other columns blank so wrapped text lines up with where it started.
+func (self *Table) wrap() {
+	widths := self.fit()
+
+	var rows [][]string
+	var sources []int
+
+	for source, row := range self.rows {
+		var lines [][]string
+
+		for i, cell := range row {
+			for j, line := range wrapText(cell, widths[i]) {
+				if j == len(lines) {
+					lines = append(lines, make([]string, len(row)))
+				}
+
+				lines[j][i] = line
+			}
+		}
+
+		rows = append(rows, lines...)
+		for range lines {
+			sources = append(sources, source)
+		}
+	}
+
+	self.rows = rows
+	self.sources = sources
+	self.lens = widths
+
+	// Headers are kept to a single line.
+	for i, header := range self.header {
+		self.header[i] = truncateWidth(header, widths[i])
+	}
+}
+
+// paint hands every cell to Paint, before they're padded out
+// so that padding is left as it is.
+func (self *Table) paint() {
+	if self.Paint == nil {
+		return
+	}
+
+	for i, cell := range self.header {
+		self.header[i] = self.Paint(-1, i, cell)
+	}
+
+	for i, row := range self.rows {
+		for j, cell := range row {
+			row[j] = self.Paint(self.sources[i], j, cell)
+		}
+	}
+}
+
+// fit works out how wide each column can be, taking a column at a
+// time from the widest of the Wrap columns until the table fits or
+// none of them can give up any more.
+func (self *Table) fit() []int {
+	widths := make([]int, len(self.lens))
+	total := int(self.Pad) * (len(self.lens) - 1)
+
+	if self.Border != BorderNone {
+		// A space either side of every cell, and
+		// a line either side of every column.
+		total = 3*len(self.lens) + 1
+	}
+
+	for i, width := range self.lens {
+		widths[i] = width
+		total += width
+	}
+
+	wrap := self.Wrap
+	if len(wrap) == 0 {
+		wrap = []int{len(widths) - 1}
+	}
+
+	for self.Width > 0 && total > self.Width {
+		widest := -1
+
+		for _, i := range wrap {
+			if i < 0 || i >= len(widths) || widths[i] <= tableWrapMinWidth {
+				continue
+			}
+
+			if widest < 0 || widths[i] > widths[widest] {
+				widest = i
+			}
+		}
+
+		if widest < 0 {
+			break
+		}
+
+		widths[widest]--
+		total--
+	}
+
+	return widths
+}
+
+// wrapText breaks text into lines no wider than width, between words
+// where possible. A word that is too long on its own gets split.
+func wrapText(text string, width int) []string {
+	if width <= 0 || displayWidth(text) <= width {
+		return []string{text}
+	}
+
+	var lines []string
+	var line string
+
+	for _, word := range strings.Fields(text) {
+		for displayWidth(word) > width {
+			if len(line) > 0 {
+				lines = append(lines, line)
+				line = ""
+			}
+
+			var head string
+			head, word = splitWidth(word, width)
+			lines = append(lines, head)
+		}
+
+		if len(line) > 0 && displayWidth(line)+1+displayWidth(word) > width {
+			lines = append(lines, line)
+			line = ""
+		}
+
+		if len(line) > 0 {
+			line += " "
+		}
+
+		line += word
+	}
+
+	if len(line) > 0 || len(lines) == 0 {
+		lines = append(lines, line)
+	}
+
+	if strings.ContainsRune(text, escape) {
+		lines = sgrBalance(lines)
+	}
+
+	return lines
+}
+
+// truncateWidth cuts text short, ending it with an ellipsis, so
+// that it is no wider than width. Escape sequences before the cut
+// are kept, and anything they leave on is reset after the ellipsis.
+func truncateWidth(text string, width int) string {
+	if displayWidth(text) <= width {
+		return text
+	}
+
+	if width <= 0 {
+		return ""
+	}
+
+	var head strings.Builder
+	taken := displayWidth(tableEllipsis)
+
+	for i := 0; i < len(text); {
+		if text[i] == escape {
+			end := ansiEnd(text, i)
+			head.WriteString(text[i : end+1])
+			i = end + 1
+			continue
+		}
+
+		end, w := cluster(text, i)
+
+		taken += w
+		if taken > width {
+			break
+		}
+
+		head.WriteString(text[i:end])
+		i = end
+	}
+
+	truncated := head.String() + tableEllipsis
+	if len(sgrActive(truncated)) > 0 {
+		truncated += sgrReset
+	}
+
+	return truncated
+}
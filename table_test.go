@@ -1,5 +1,8 @@
 package cligobrr
 
+import "bytes"
+import "fmt"
+import "strings"
 import "testing"
 import "github.com/stretchr/testify/assert"
 
@@ -11,7 +14,7 @@ func TestTableNew(t *testing.T) {
 		Pad:  3,
 	}
 
-	table, err := tableNew(fields)
+	table, err := TableNew(fields)
 	assert.NotNil(table)
 	assert.Nil(err)
 	assert.Equal(fields.Cols, table.Cols)
@@ -24,7 +27,7 @@ func TestTableNewZeroCols(t *testing.T) {
 
 	fields := TableFields{}
 
-	table, err := tableNew(fields)
+	table, err := TableNew(fields)
 	assert.Nil(table)
 	assert.NotNil(err)
 }
@@ -36,7 +39,7 @@ func TestTableNewZeroPad(t *testing.T) {
 		Cols: 3,
 	}
 
-	table, err := tableNew(fields)
+	table, err := TableNew(fields)
 	assert.NotNil(table)
 	assert.Nil(err)
 	assert.Equal(tablePadDefault, table.Pad)
@@ -50,7 +53,7 @@ func TestTableAdd(t *testing.T) {
 		Cols: 3,
 	}
 
-	table, err := tableNew(fields)
+	table, err := TableNew(fields)
 	assert.Nil(err)
 
 	row := []string{"one", "two", "three"}
@@ -67,7 +70,7 @@ func TestTableAddIncorrectCols(t *testing.T) {
 		Cols: 3,
 	}
 
-	table, err := tableNew(fields)
+	table, err := TableNew(fields)
 	assert.Nil(err)
 
 	row := []string{"one", "two"}
@@ -83,7 +86,7 @@ func TestTableNormalize(t *testing.T) {
 		Cols: 3,
 	}
 
-	table, err := tableNew(fields)
+	table, err := TableNew(fields)
 	assert.Nil(err)
 
 	row1 := []string{"one", "two", "three"}
@@ -106,7 +109,7 @@ func TestTableToString(t *testing.T) {
 		Cols: 3,
 	}
 
-	table, err := tableNew(fields)
+	table, err := TableNew(fields)
 	assert.Nil(err)
 
 	row1 := []string{"one", "two", "three"}
@@ -121,3 +124,348 @@ func TestTableToString(t *testing.T) {
 	actual := table.ToString()
 	assert.Equal(expected, actual)
 }
+
+func TestTableWrap(t *testing.T) {
+	assert := assert.New(t)
+
+	fields := TableFields{
+		Cols:  2,
+		Width: 30,
+	}
+
+	table, err := TableNew(fields)
+	assert.Nil(err)
+
+	table.Add([]string{"deploy", "Deploy the current build to every cluster."})
+	table.Add([]string{"status", "Show status."})
+
+	expected := strings.Join([]string{
+		"deploy    Deploy the current  ",
+		"          build to every      ",
+		"          cluster.            ",
+		"status    Show status.        ",
+	}, "\n")
+
+	assert.Equal(expected, table.ToString())
+}
+
+func TestTableWrapColumns(t *testing.T) {
+	assert := assert.New(t)
+
+	fields := TableFields{
+		Cols:  3,
+		Pad:   1,
+		Width: 30,
+		Wrap:  []int{1},
+	}
+
+	table, err := TableNew(fields)
+	assert.Nil(err)
+
+	table.Add([]string{"a", "one two three four five six", "end"})
+
+	expected := strings.Join([]string{
+		"a one two three four five  end",
+		"  six                         ",
+	}, "\n")
+
+	assert.Equal(expected, table.ToString())
+}
+
+func TestTableWrapMinWidth(t *testing.T) {
+	assert := assert.New(t)
+
+	fields := TableFields{
+		Cols:  2,
+		Width: 10,
+	}
+
+	table, err := TableNew(fields)
+	assert.Nil(err)
+
+	table.Add([]string{"name", "a description that is long"})
+
+	// The description can't go narrower than the minimum,
+	// even though that means the table doesn't fit.
+	for _, line := range strings.Split(table.ToString(), "\n") {
+		assert.Equal(len("name")+int(tablePadDefault)+tableWrapMinWidth, len(line))
+	}
+}
+
+func TestWrapText(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal([]string{"short"}, wrapText("short", 10))
+	assert.Equal([]string{"one two", "three"}, wrapText("one two three", 7))
+	assert.Equal([]string{"abcde", "fghij", "k"}, wrapText("abcdefghijk", 5))
+	assert.Equal([]string{"ab", "cdefg", "hij"}, wrapText("ab cdefghij", 5))
+	assert.Equal([]string{"anything"}, wrapText("anything", 0))
+
+	// Colour is carried over from line to line, and turned off
+	// at the end of each, however the text was split.
+	assert.Equal([]string{
+		"\x1b[31mabcde\x1b[0m",
+		"\x1b[31mfghij\x1b[0m",
+	}, wrapText("\x1b[31mabcdefghij\x1b[0m", 5))
+
+	assert.Equal([]string{
+		"\x1b[1mone \x1b[32mtwo\x1b[0m",
+		"\x1b[1m\x1b[32mthree\x1b[0m",
+		"four",
+	}, wrapText("\x1b[1mone \x1b[32mtwo three\x1b[0m four", 7))
+}
+
+func TestTableUnicode(t *testing.T) {
+	assert := assert.New(t)
+
+	table, err := TableNew(TableFields{Cols: 2})
+	assert.Nil(err)
+
+	table.Add([]string{"Größe", "size"})
+	table.Add([]string{"サイズ", "size"})
+	table.Add([]string{"\x1b[1msize\x1b[0m", "size"})
+
+	expected := strings.Join([]string{
+		"Größe     size",
+		"サイズ    size",
+		"\x1b[1msize\x1b[0m      size",
+	}, "\n")
+
+	assert.Equal(expected, table.ToString())
+}
+
+func TestTableLongCells(t *testing.T) {
+	assert := assert.New(t)
+
+	table, err := TableNew(TableFields{Cols: 2})
+	assert.Nil(err)
+
+	long := strings.Repeat("x", 300)
+	table.Add([]string{long, "a"})
+	table.Add([]string{"short", "b"})
+
+	lines := strings.Split(table.ToString(), "\n")
+	assert.Equal(long+"    a", lines[0])
+	assert.Equal(len(lines[0]), len(lines[1]))
+}
+
+func TestTableNewHeadersIncorrectCols(t *testing.T) {
+	assert := assert.New(t)
+
+	table, err := TableNew(TableFields{Cols: 2, Headers: []string{"one"}})
+	assert.Nil(table)
+	assert.NotNil(err)
+}
+
+func TestTableHeaders(t *testing.T) {
+	assert := assert.New(t)
+
+	table, err := TableNew(TableFields{Cols: 2, Headers: []string{"Name", "Description"}})
+	assert.Nil(err)
+
+	table.Add([]string{"deploy", "Deploy."})
+
+	expected := strings.Join([]string{
+		"Name      Description",
+		"----      -----------",
+		"deploy    Deploy.    ",
+	}, "\n")
+
+	assert.Equal(expected, table.ToString())
+}
+
+func TestTableAlign(t *testing.T) {
+	assert := assert.New(t)
+
+	fields := TableFields{
+		Cols:    3,
+		Pad:     1,
+		Headers: []string{"Name", "Count", "State"},
+		Align:   []Align{AlignLeft, AlignRight, AlignCenter},
+	}
+
+	table, err := TableNew(fields)
+	assert.Nil(err)
+
+	table.Add([]string{"a", "7", "up"})
+	table.Add([]string{"bb", "1234", "down"})
+
+	expected := strings.Join([]string{
+		"Name Count State",
+		"---- ----- -----",
+		"a        7  up  ",
+		"bb    1234 down ",
+	}, "\n")
+
+	assert.Equal(expected, table.ToString())
+}
+
+func TestTableBorders(t *testing.T) {
+	assert := assert.New(t)
+
+	fields := TableFields{
+		Cols:    2,
+		Headers: []string{"Name", "Count"},
+		Align:   []Align{AlignLeft, AlignRight},
+		Border:  BorderASCII,
+	}
+
+	table, err := TableNew(fields)
+	assert.Nil(err)
+
+	table.Add([]string{"deploy", "3"})
+
+	expected := strings.Join([]string{
+		"+--------+-------+",
+		"| Name   | Count |",
+		"+--------+-------+",
+		"| deploy |     3 |",
+		"+--------+-------+",
+	}, "\n")
+
+	assert.Equal(expected, table.ToString())
+
+	fields.Border = BorderBox
+	fields.Headers = nil
+
+	table, err = TableNew(fields)
+	assert.Nil(err)
+
+	table.Add([]string{"deploy", "3"})
+
+	expected = strings.Join([]string{
+		"┌────────┬───┐",
+		"│ deploy │ 3 │",
+		"└────────┴───┘",
+	}, "\n")
+
+	assert.Equal(expected, table.ToString())
+}
+
+func TestTableBordersWrap(t *testing.T) {
+	assert := assert.New(t)
+
+	table, err := TableNew(TableFields{Cols: 2, Border: BorderASCII, Width: 22})
+	assert.Nil(err)
+
+	table.Add([]string{"a", "one two three four"})
+
+	expected := strings.Join([]string{
+		"+---+----------------+",
+		"| a | one two three  |",
+		"|   | four           |",
+		"+---+----------------+",
+	}, "\n")
+
+	assert.Equal(expected, table.ToString())
+}
+
+func TestTableMaxWidths(t *testing.T) {
+	assert := assert.New(t)
+
+	fields := TableFields{
+		Cols:      2,
+		Headers:   []string{"Name", "Description"},
+		MaxWidths: []int{0, 8},
+	}
+
+	table, err := TableNew(fields)
+	assert.Nil(err)
+
+	table.Add([]string{"deploy", "Deploy everything."})
+	table.Add([]string{"logs", "Logs."})
+
+	expected := strings.Join([]string{
+		"Name      Descrip…",
+		"----      --------",
+		"deploy    Deploy …",
+		"logs      Logs.   ",
+	}, "\n")
+
+	assert.Equal(expected, table.ToString())
+}
+
+func TestTruncateWidth(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("short", truncateWidth("short", 5))
+	assert.Equal("shor…", truncateWidth("shorter", 5))
+	assert.Equal("日…", truncateWidth("日本語", 4))
+	assert.Equal("…", truncateWidth("日本語", 2))
+	assert.Equal("", truncateWidth("日本語", 0))
+
+	// Joined emoji and flags are cut whole or not at all.
+	assert.Equal("ab…", truncateWidth("ab👩‍💻cd", 4))
+	assert.Equal("ab👩‍💻…", truncateWidth("ab👩‍💻cd", 5))
+	assert.Equal("🇩🇪…", truncateWidth("🇩🇪🇯🇵", 3))
+	assert.Equal("…", truncateWidth("🇩🇪🇯🇵", 2))
+
+	// Escape sequences take no room, and colour is reset at the end.
+	assert.Equal("\x1b[31mabcd…\x1b[0m", truncateWidth("\x1b[31mabcdefghij\x1b[0m", 5))
+	assert.Equal("\x1b[31mab\x1b[0mcd…", truncateWidth("\x1b[31mab\x1b[0mcdefghij", 5))
+	assert.Equal("\x1b[1mshort\x1b[0m", truncateWidth("\x1b[1mshort\x1b[0m", 5))
+}
+
+func TestTableWriteTo(t *testing.T) {
+	assert := assert.New(t)
+
+	var out bytes.Buffer
+
+	table, err := TableNew(TableFields{Cols: 2})
+	assert.Nil(err)
+
+	table.Add([]string{"one", "two"})
+
+	n, err := table.WriteTo(&out)
+	assert.Nil(err)
+	assert.Equal("one    two\n", out.String())
+	assert.Equal(int64(out.Len()), n)
+}
+
+func TestTablePaint(t *testing.T) {
+	assert := assert.New(t)
+
+	var painted []int
+
+	table, err := TableNew(TableFields{
+		Cols:    2,
+		Headers: []string{"Name", "Description"},
+		Width:   30,
+		Paint: func(row int, col int, cell string) string {
+			if col == 0 {
+				painted = append(painted, row)
+				return fmt.Sprintf("\x1b[1m%s\x1b[0m", cell)
+			}
+
+			return cell
+		},
+	})
+	assert.Nil(err)
+
+	table.Add([]string{"api", "A description long enough to wrap."})
+	table.Add([]string{"db", "Short."})
+
+	expected := strings.Join([]string{
+		"\x1b[1mName\x1b[0m    Description           ",
+		"----    -----------           ",
+		"\x1b[1mapi\x1b[0m     A description long    ",
+		"\x1b[1m\x1b[0m        enough to wrap.       ",
+		"\x1b[1mdb\x1b[0m      Short.                ",
+	}, "\n")
+
+	assert.Equal(expected, table.ToString())
+
+	// Both lines of the wrapped row are from the first row added.
+	assert.Equal([]int{-1, 0, 0, 1}, painted)
+
+	// Rendering again paints the cells as they were added, not
+	// what was painted last time, and rows still map to sources.
+	painted = nil
+	assert.Equal(expected, table.ToString())
+	assert.Equal([]int{-1, 0, 0, 1}, painted)
+
+	painted = nil
+	table.Add([]string{"cache", "Added later."})
+	assert.Contains(table.ToString(), "\x1b[1mcache\x1b[0m    Added later.")
+	assert.Equal([]int{-1, 0, 0, 1, 2}, painted)
+}
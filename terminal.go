@@ -0,0 +1,44 @@
+package cligobrr
+
+import "io"
+import "os"
+import "strconv"
+import "strings"
+
+// terminalDetect asks the terminal behind fd how wide it is. It's a
+// variable so tests don't depend on whatever they happen to be run in.
+var terminalDetect = terminalSize
+
+// terminalCheck reports whether out is a terminal. It's a variable
+// for the same reason terminalDetect is.
+var terminalCheck = isTerminal
+
+// terminalWidth is how wide output to out is allowed to get, or 0
+// for no limit. Only a terminal has a width to fit: files, pipes and
+// buffers aren't wrapped at all. COLUMNS overrides the terminal's own
+// size, and a terminal that won't say gets a safe default.
+func terminalWidth(out io.Writer) int {
+	if !terminalCheck(out) {
+		return 0
+	}
+
+	columns, err := strconv.Atoi(strings.TrimSpace(os.Getenv("COLUMNS")))
+	if err == nil && columns > 0 {
+		return columns
+	}
+
+	file, ok := out.(interface{ Fd() uintptr })
+	if ok {
+		columns, ok := terminalDetect(file.Fd())
+		if ok && columns > 0 {
+			return columns
+		}
+	}
+
+	return terminalWidthDefault
+}
+
+func isTerminal(out io.Writer) bool {
+	file, ok := out.(interface{ Fd() uintptr })
+	return ok && terminalIs(file.Fd())
+}
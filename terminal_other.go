@@ -0,0 +1,15 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package cligobrr
+
+// terminalSize can't ask the terminal on this platform, so
+// COLUMNS or the default will have to do.
+func terminalSize(fd uintptr) (int, bool) {
+	return 0, false
+}
+
+// terminalIs can't tell a terminal from anything else on this
+// platform, so color has to be asked for with color=always.
+func terminalIs(fd uintptr) bool {
+	return false
+}
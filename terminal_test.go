@@ -0,0 +1,109 @@
+package cligobrr
+
+import "bytes"
+import "io"
+import "os"
+import "strings"
+import "testing"
+import "github.com/stretchr/testify/assert"
+
+// testTerminal is a buffer that passes for a terminal, so tests can
+// see how output gets wrapped without needing a real one.
+type testTerminal struct {
+	bytes.Buffer
+}
+
+func (self *testTerminal) Fd() uintptr {
+	return ^uintptr(0)
+}
+
+// testTerminalNew makes a testTerminal that says it's columns wide,
+// or that won't say at all when columns is 0.
+func testTerminalNew(t *testing.T, columns int) *testTerminal {
+	check, detect := terminalCheck, terminalDetect
+	t.Cleanup(func() { terminalCheck, terminalDetect = check, detect })
+
+	terminal := &testTerminal{}
+
+	terminalCheck = func(out io.Writer) bool {
+		return out == io.Writer(terminal) || check(out)
+	}
+
+	terminalDetect = func(fd uintptr) (int, bool) {
+		return columns, columns > 0
+	}
+
+	t.Setenv("COLUMNS", "")
+	t.Setenv("NO_COLOR", "1")
+
+	return terminal
+}
+
+func TestTerminalWidth(t *testing.T) {
+	assert := assert.New(t)
+
+	terminal := testTerminalNew(t, 132)
+	assert.Equal(132, terminalWidth(terminal))
+
+	// COLUMNS wins over the terminal's own size.
+	t.Setenv("COLUMNS", "100")
+	assert.Equal(100, terminalWidth(terminal))
+
+	t.Setenv("COLUMNS", "nope")
+	assert.Equal(132, terminalWidth(terminal))
+
+	// Anything else isn't wrapped, whatever COLUMNS says.
+	t.Setenv("COLUMNS", "100")
+	assert.Equal(0, terminalWidth(&bytes.Buffer{}))
+
+	file, err := os.CreateTemp(t.TempDir(), "out")
+	assert.Nil(err)
+	defer file.Close()
+
+	assert.Equal(0, terminalWidth(file))
+}
+
+func TestTerminalWidthUnknown(t *testing.T) {
+	terminal := testTerminalNew(t, 0)
+	assert.Equal(t, terminalWidthDefault, terminalWidth(terminal))
+}
+
+func TestTerminalWidthFollowsWriter(t *testing.T) {
+	assert := assert.New(t)
+
+	terminal := testTerminalNew(t, 50)
+
+	var out bytes.Buffer
+
+	app := AppNew(AppFields{Name: testAppName, Out: &out, Err: terminal, Output: true})
+
+	description := "Deploy the current build to every cluster in every region."
+
+	cmd, _ := CmdNew(CmdFields{
+		Name:        "deploy",
+		Description: description,
+		ExecWithOutput: func(ctx *Context) (any, error) {
+			return map[string]string{"description": description}, nil
+		},
+	})
+	app.Cmds.Add(cmd)
+
+	// Help written to a buffer is left as it is...
+	assert.Nil(app.Run([]string{testAppName, "help"}))
+	assert.Contains(out.String(), description)
+
+	// ...but wrapped when it goes to a terminal, even if that's Err.
+	result, err := app.Resolve([]string{testAppName, "help"})
+	assert.Nil(err)
+	assert.Nil(result.RenderTo(app.Err))
+	assert.NotContains(terminal.String(), description)
+
+	for _, line := range strings.Split(terminal.String(), "\n") {
+		assert.LessOrEqual(displayWidth(line), 50)
+	}
+
+	// The same goes for tables of records.
+	out.Reset()
+	assert.Nil(app.Run([]string{testAppName, "deploy"}))
+	assert.Contains(out.String(), description)
+}
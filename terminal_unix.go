@@ -0,0 +1,41 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package cligobrr
+
+import "syscall"
+import "unsafe"
+
+type terminalWinsize struct {
+	rows, cols, xpixels, ypixels uint16
+}
+
+// terminalSize asks the terminal behind fd for its size. It fails
+// when fd isn't a terminal, e.g. when it's a file or a pipe.
+func terminalSize(fd uintptr) (int, bool) {
+	size, ok := terminalGetWinsize(fd)
+	if !ok || size.cols == 0 {
+		return 0, false
+	}
+
+	return int(size.cols), true
+}
+
+// terminalIs reports whether fd is a terminal, which is
+// the only thing that will tell us its size.
+func terminalIs(fd uintptr) bool {
+	_, ok := terminalGetWinsize(fd)
+	return ok
+}
+
+func terminalGetWinsize(fd uintptr) (terminalWinsize, bool) {
+	var size terminalWinsize
+
+	_, _, errno := syscall.Syscall(
+		syscall.SYS_IOCTL,
+		fd,
+		uintptr(syscall.TIOCGWINSZ),
+		uintptr(unsafe.Pointer(&size)),
+	)
+
+	return size, errno == 0
+}
@@ -0,0 +1,153 @@
+package cligobrr
+
+import "fmt"
+import "strings"
+
+// usage builds the full command line for cmd or, when cmd is nil, for
+// app, wrapped to width, if there is one. Something like:
+//
+//	myApp [global args] deploy status region=<us|eu> [tags=<string>,...]
+//
+// When commands is true, a placeholder for subcommands is included if
+// there are any to choose from.
+func usage(app *App, cmd *Cmd, args []IArg, commands bool, width int) []string {
+	prefix, fragments := usageParts(app, cmd, args, commands)
+	return usageWrap(prefix, fragments, width)
+}
+
+// usageParts is usage before it's wrapped: the names leading up to
+// the args, and the args (and commands placeholder) themselves.
+func usageParts(app *App, cmd *Cmd, args []IArg, commands bool) (string, []string) {
+	var prefix []string
+
+	if app != nil {
+		prefix = append(prefix, app.Name)
+	}
+
+	if cmd != nil {
+		if app != nil && len(visibleArgs(app.Args.args, false)) > 0 {
+			prefix = append(prefix, usageGlobalArgs)
+		}
+
+		prefix = append(prefix, cmdNames(cmd)...)
+	}
+
+	var fragments []string
+	for _, arg := range args {
+		fragments = append(fragments, usageArg(arg))
+	}
+
+	if commands {
+		fragment := usageCmds(app, cmd)
+		if len(fragment) > 0 {
+			fragments = append(fragments, fragment)
+		}
+	}
+
+	return strings.Join(prefix, " "), fragments
+}
+
+// usageArg renders a single arg, e.g. 'region=<us|eu>' when it
+// is required or '[tags=<string>,...]' when it is not.
+func usageArg(arg IArg) string {
+	fragment := fmt.Sprintf("%s=<%s>", arg.GetName(), usagePlaceholder(arg))
+
+	if arg.GetMultiple() {
+		fragment = fmt.Sprintf("%s%s...", fragment, arg.GetSeparator())
+	}
+
+	if !arg.GetRequired() {
+		fragment = fmt.Sprintf("[%s]", fragment)
+	}
+
+	return fragment
+}
+
+// usagePlaceholder names an arg's value: the Placeholder if there is
+// one, otherwise the choices or, failing that, the kind.
+func usagePlaceholder(arg IArg) string {
+	placeholder := arg.GetPlaceholder()
+	if len(placeholder) > 0 {
+		return placeholder
+	}
+
+	choices := currentChoices(arg)
+	if len(choices) > 0 {
+		return strings.Join(choices, "|")
+	}
+
+	return arg.GetKind()
+}
+
+// usageCmds renders the placeholder for a subcommand, which is
+// optional when there is something to do without one.
+func usageCmds(app *App, cmd *Cmd) string {
+	var cmds Cmds
+	optional := false
+
+	if cmd != nil {
+		cmds = cmd.Cmds
+		optional = cmd.executable()
+	} else if app != nil {
+		cmds = app.Cmds
+	}
+
+	userCmds := 0
+	for _, c := range visibleCmds(cmds.cmds, false) {
+		if !c.builtin {
+			userCmds++
+		}
+	}
+
+	if userCmds == 0 {
+		return ""
+	}
+
+	if optional || cmds.defaultCmd() != nil {
+		return fmt.Sprintf("[%s]", usageCommand)
+	}
+
+	return usageCommand
+}
+
+// cmdNames is Path without the app name.
+func cmdNames(cmd *Cmd) []string {
+	var names []string
+
+	for ; cmd != nil; cmd = cmd.parent {
+		names = append([]string{cmd.Name}, names...)
+	}
+
+	return names
+}
+
+// usageWrap lays fragments out after prefix, starting a new line
+// whenever the next one won't fit in width, unless width is 0.
+// Continuation lines are indented to line up with the first
+// fragment, unless that would leave too little room, in which case
+// a plain indent is used instead.
+func usageWrap(prefix string, fragments []string, width int) []string {
+	indent := displayWidth(prefix) + 1
+	if width-indent < usageMinWidth {
+		indent = usageIndent
+	}
+
+	padding := strings.Repeat(" ", indent)
+
+	var lines []string
+	line := prefix
+
+	for _, fragment := range fragments {
+		switch {
+		case len(line) == 0:
+			line = fragment
+		case width > 0 && displayWidth(line)+1+displayWidth(fragment) > width:
+			lines = append(lines, line)
+			line = padding + fragment
+		default:
+			line = fmt.Sprintf("%s %s", line, fragment)
+		}
+	}
+
+	return append(lines, line)
+}
@@ -0,0 +1,102 @@
+package cligobrr
+
+import "testing"
+import "github.com/stretchr/testify/assert"
+
+func TestUsageArg(t *testing.T) {
+	assert := assert.New(t)
+
+	arg, _ := StringArgNew(ArgFields{Name: "region", Required: true, Choices: []string{"us", "eu"}})
+	assert.Equal("region=<us|eu>", usageArg(arg))
+
+	arg, _ = StringArgNew(ArgFields{Name: "tags", Multiple: true})
+	assert.Equal("[tags=<string>,...]", usageArg(arg))
+
+	arg, _ = StringArgNew(ArgFields{Name: "target", Required: true, Placeholder: "host"})
+	assert.Equal("target=<host>", usageArg(arg))
+
+	arg, _ = IntArgNew(ArgFields{Name: "ports", Multiple: true, Separator: ":"})
+	assert.Equal("[ports=<int>:...]", usageArg(arg))
+
+	arg, _ = StringArgNew(ArgFields{
+		Name:              "region",
+		Choices:           []string{"us", "eu", "usa"},
+		DeprecatedChoices: map[string]Deprecation{"usa": {}},
+	})
+	assert.Equal("[region=<us|eu>]", usageArg(arg))
+}
+
+func TestUsage(t *testing.T) {
+	assert := assert.New(t)
+
+	app := AppNew(AppFields{Name: testAppName})
+
+	verbose, _ := BoolArgNew(ArgFields{Name: "verbose"})
+	app.Args.Add(verbose)
+
+	deploy, _ := CmdNew(CmdFields{Name: "deploy"})
+	status, _ := CmdNew(CmdFields{Name: "status", Exec: testCmdExec})
+	region, _ := StringArgNew(ArgFields{Name: "region", Required: true, Choices: []string{"us", "eu"}})
+	tags, _ := StringArgNew(ArgFields{Name: "tags", Multiple: true})
+
+	status.Args.Add(region)
+	status.Args.Add(tags)
+	deploy.Cmds.Add(status)
+	app.Cmds.Add(deploy)
+
+	assert.Equal(
+		[]string{"myApp [verbose=<bool>] <command>"},
+		usage(app, nil, app.Args.List(), true, 200),
+	)
+
+	assert.Equal(
+		[]string{"myApp [global args] deploy <command>"},
+		usage(app, deploy, nil, true, 200),
+	)
+
+	assert.Equal(
+		[]string{"myApp [global args] deploy status region=<us|eu> [tags=<string>,...]"},
+		usage(app, status, status.Args.List(), true, 200),
+	)
+
+	// Being executable makes a subcommand optional.
+	deploy.Exec = testCmdExec
+	assert.Equal(
+		[]string{"myApp [global args] deploy [<command>]"},
+		usage(app, deploy, nil, true, 200),
+	)
+
+	// Without an app, there is only the command path.
+	other, _ := CmdNew(CmdFields{Name: "other"})
+	assert.Equal([]string{"other"}, usage(nil, other, nil, true, 200))
+}
+
+func TestUsageWrap(t *testing.T) {
+	assert := assert.New(t)
+
+	fragments := []string{"region=<us|eu>", "[tags=<string>,...]", "[verbose=<bool>]"}
+
+	assert.Equal(
+		[]string{"myApp deploy region=<us|eu> [tags=<string>,...] [verbose=<bool>]"},
+		usageWrap("myApp deploy", fragments, 80),
+	)
+
+	assert.Equal(
+		[]string{
+			"myApp deploy region=<us|eu>",
+			"             [tags=<string>,...]",
+			"             [verbose=<bool>]",
+		},
+		usageWrap("myApp deploy", fragments, 40),
+	)
+
+	// Not enough room to line up, so fall back to a plain indent.
+	assert.Equal(
+		[]string{
+			"myApp deploy region=<us|eu>",
+			"    [tags=<string>,...]",
+			"    [verbose=<bool>]",
+		},
+		usageWrap("myApp deploy", fragments, 30),
+	)
+}
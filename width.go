@@ -0,0 +1,320 @@
+package cligobrr
+
+import "strings"
+import "unicode"
+import "unicode/utf8"
+
+// displayWidth is how many terminal columns s takes up, which isn't
+// the same as how many bytes or even runes are in it: ANSI escape
+// sequences take up none, combining marks and the rest of a grapheme
+// cluster add nothing to the character they belong to, and East Asian
+// wide characters (and most emoji) take up two.
+func displayWidth(s string) int {
+	s = stripANSI(s)
+	width := 0
+
+	for i := 0; i < len(s); {
+		end, w := cluster(s, i)
+		width += w
+		i = end
+	}
+
+	return width
+}
+
+// cluster returns where the grapheme cluster starting at start ends,
+// and how wide it is. That's a character and any marks, modifiers and
+// variation selectors after it, along with whatever zero width joiners
+// join on, e.g. a family emoji, or a pair of regional indicators,
+// which together make a flag. Anything measuring or cutting text goes
+// a cluster at a time, so none of these is ever split up.
+func cluster(s string, start int) (int, int) {
+	r, size := utf8.DecodeRuneInString(s[start:])
+	i := start + size
+
+	width := runeWidth(r)
+	joined := r == zeroWidthJoiner
+
+	// Flags are as wide as any other emoji.
+	if isRegionalIndicator(r) {
+		width = 2
+
+		next, size := utf8.DecodeRuneInString(s[i:])
+		if isRegionalIndicator(next) {
+			i += size
+		}
+	}
+
+	for i < len(s) && s[i] != escape {
+		r, size := utf8.DecodeRuneInString(s[i:])
+
+		switch {
+		case joined:
+			joined = false
+		case r == zeroWidthJoiner:
+			joined = true
+		case runeWidth(r) != 0 || unicode.IsControl(r):
+			return i, width
+		}
+
+		i += size
+	}
+
+	return i, width
+}
+
+// runeWidth is the width of a rune on its own.
+func runeWidth(r rune) int {
+	switch {
+	case r == utf8.RuneError:
+		return 1
+	case unicode.IsControl(r):
+		return 0
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf):
+		return 0
+	case isVariationSelector(r) || isEmojiModifier(r) || isHangulJamoTrailing(r):
+		return 0
+	case isWide(r):
+		return 2
+	}
+
+	return 1
+}
+
+const zeroWidthJoiner = '\u200d'
+
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1f1e6 && r <= 0x1f1ff
+}
+
+func isVariationSelector(r rune) bool {
+	return (r >= 0xfe00 && r <= 0xfe0f) || (r >= 0xe0100 && r <= 0xe01ef)
+}
+
+func isEmojiModifier(r rune) bool {
+	return r >= 0x1f3fb && r <= 0x1f3ff
+}
+
+// Hangul vowels and final consonants combine with the leading
+// consonant before them into a single syllable.
+func isHangulJamoTrailing(r rune) bool {
+	return r >= 0x1160 && r <= 0x11ff
+}
+
+// wideRanges are the East Asian Wide and Fullwidth blocks, along with
+// the emoji blocks that terminals draw two columns wide.
+var wideRanges = [][2]rune{
+	{0x1100, 0x115f},   // Hangul Jamo leading consonants
+	{0x231a, 0x231b},   // watch, hourglass
+	{0x2329, 0x232a},   // angle brackets
+	{0x23e9, 0x23ec},   // media controls
+	{0x23f0, 0x23f0},   // alarm clock
+	{0x23f3, 0x23f3},   // hourglass
+	{0x25fd, 0x25fe},   // small squares
+	{0x2614, 0x2615},   // umbrella, hot beverage
+	{0x2648, 0x2653},   // zodiac
+	{0x267f, 0x267f},   // wheelchair
+	{0x2693, 0x2693},   // anchor
+	{0x26a1, 0x26a1},   // high voltage
+	{0x26aa, 0x26ab},   // circles
+	{0x26bd, 0x26be},   // balls
+	{0x26c4, 0x26c5},   // snowman, sun
+	{0x26ce, 0x26ce},   // ophiuchus
+	{0x26d4, 0x26d4},   // no entry
+	{0x26ea, 0x26ea},   // church
+	{0x26f2, 0x26f3},   // fountain, golf
+	{0x26f5, 0x26f5},   // sailboat
+	{0x26fa, 0x26fa},   // tent
+	{0x26fd, 0x26fd},   // fuel pump
+	{0x2705, 0x2705},   // check mark
+	{0x270a, 0x270b},   // fists
+	{0x2728, 0x2728},   // sparkles
+	{0x274c, 0x274c},   // cross mark
+	{0x274e, 0x274e},   // cross mark
+	{0x2753, 0x2755},   // question and exclamation marks
+	{0x2757, 0x2757},   // exclamation mark
+	{0x2795, 0x2797},   // plus, minus, divide
+	{0x27b0, 0x27b0},   // curly loop
+	{0x27bf, 0x27bf},   // double curly loop
+	{0x2b1b, 0x2b1c},   // large squares
+	{0x2b50, 0x2b50},   // star
+	{0x2b55, 0x2b55},   // circle
+	{0x2e80, 0x303e},   // CJK radicals, Kangxi, CJK symbols
+	{0x3041, 0x33ff},   // Hiragana, Katakana, Bopomofo, Hangul compatibility, CJK compatibility
+	{0x3400, 0x4dbf},   // CJK extension A
+	{0x4e00, 0x9fff},   // CJK unified ideographs
+	{0xa000, 0xa4cf},   // Yi
+	{0xa960, 0xa97f},   // Hangul Jamo extended A
+	{0xac00, 0xd7a3},   // Hangul syllables
+	{0xf900, 0xfaff},   // CJK compatibility ideographs
+	{0xfe10, 0xfe19},   // vertical forms
+	{0xfe30, 0xfe6f},   // CJK compatibility forms, small forms
+	{0xff00, 0xff60},   // fullwidth forms
+	{0xffe0, 0xffe6},   // fullwidth signs
+	{0x16fe0, 0x16fe4}, // ideographic symbols
+	{0x17000, 0x18cff}, // Tangut
+	{0x1b000, 0x1b2ff}, // Kana supplement and extensions, Nushu
+	{0x1f004, 0x1f004}, // mahjong tile
+	{0x1f0cf, 0x1f0cf}, // playing card
+	{0x1f18e, 0x1f18e}, // AB button
+	{0x1f191, 0x1f19a}, // squared words
+	{0x1f200, 0x1f2ff}, // enclosed ideographic supplement
+	{0x1f300, 0x1f64f}, // pictographs, emoticons
+	{0x1f680, 0x1f6ff}, // transport and map
+	{0x1f7e0, 0x1f7eb}, // coloured circles and squares
+	{0x1f90c, 0x1f9ff}, // supplemental symbols and pictographs
+	{0x1fa70, 0x1faff}, // symbols and pictographs extended A
+	{0x20000, 0x2fffd}, // CJK extensions B to F
+	{0x30000, 0x3fffd}, // CJK extension G and beyond
+}
+
+func isWide(r rune) bool {
+	for _, wide := range wideRanges {
+		if r < wide[0] {
+			return false
+		}
+
+		if r <= wide[1] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// stripANSI removes escape sequences, like those used for colour,
+// which the terminal acts on rather than displays.
+func stripANSI(s string) string {
+	if !strings.ContainsRune(s, escape) {
+		return s
+	}
+
+	var b strings.Builder
+
+	for i := 0; i < len(s); i++ {
+		if s[i] != escape {
+			b.WriteByte(s[i])
+			continue
+		}
+
+		i = ansiEnd(s, i)
+	}
+
+	return b.String()
+}
+
+const escape = '\x1b'
+
+// ansiEnd returns the index of the last byte of the escape
+// sequence starting at start.
+func ansiEnd(s string, start int) int {
+	i := start + 1
+	if i >= len(s) {
+		return start
+	}
+
+	switch s[i] {
+	case '[':
+		// CSI: parameters, then a single final byte.
+		for i++; i < len(s); i++ {
+			if s[i] >= 0x40 && s[i] <= 0x7e {
+				return i
+			}
+		}
+	case ']':
+		// OSC: ends with BEL or ST (ESC \).
+		for i++; i < len(s); i++ {
+			if s[i] == '\a' {
+				return i
+			}
+
+			if s[i] == escape && i+1 < len(s) && s[i+1] == '\\' {
+				return i + 1
+			}
+		}
+	default:
+		// Anything else is the escape and one more byte.
+		return i
+	}
+
+	return len(s) - 1
+}
+
+// splitWidth splits s so the first part is no wider than width,
+// though always at least one cluster so that progress is made. Escape
+// sequences take up no room, so they stay with the part before them.
+func splitWidth(s string, width int) (string, string) {
+	taken := 0
+	visible := false
+
+	for i := 0; i < len(s); {
+		if s[i] == escape {
+			i = ansiEnd(s, i) + 1
+			continue
+		}
+
+		end, w := cluster(s, i)
+		if visible && taken+w > width {
+			return s[:i], s[i:]
+		}
+
+		taken += w
+		visible = true
+		i = end
+	}
+
+	return s, ""
+}
+
+const sgrReset = "\x1b[0m"
+
+// sgrActive returns the SGR sequences, like colours, still in effect
+// at the end of s: every one since the last reset.
+func sgrActive(s string) string {
+	var active strings.Builder
+
+	for i := 0; i < len(s); i++ {
+		if s[i] != escape {
+			continue
+		}
+
+		end := ansiEnd(s, i)
+		sequence := s[i : end+1]
+		i = end
+
+		if !strings.HasPrefix(sequence, "\x1b[") || !strings.HasSuffix(sequence, "m") {
+			continue
+		}
+
+		params := sequence[2 : len(sequence)-1]
+		if len(params) == 0 || params == "0" {
+			active.Reset()
+			continue
+		}
+
+		active.WriteString(sequence)
+	}
+
+	return active.String()
+}
+
+// sgrBalance makes each of lines, once cut from the same text, stand
+// on its own: anything still in effect at the end of a line is reset
+// there and started again at the beginning of the next, so colour
+// never bleeds into padding or the cells alongside.
+func sgrBalance(lines []string) []string {
+	active := ""
+
+	for i, line := range lines {
+		line = active + line
+
+		active = sgrActive(line)
+		if len(active) > 0 {
+			line += sgrReset
+		}
+
+		lines[i] = line
+	}
+
+	return lines
+}
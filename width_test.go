@@ -0,0 +1,87 @@
+package cligobrr
+
+import "testing"
+import "github.com/stretchr/testify/assert"
+
+func TestDisplayWidth(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(0, displayWidth(""))
+	assert.Equal(6, displayWidth("deploy"))
+	assert.Equal(10, displayWidth("Größenwahn"))
+	assert.Equal(10, displayWidth("日本語です"))
+	assert.Equal(6, displayWidth("ｆｕｌ"))
+	assert.Equal(4, displayWidth("한국"))
+
+	// Combining marks belong to the letter before them.
+	assert.Equal(4, displayWidth("café"))
+
+	// Emoji, with modifiers, joiners and flags.
+	assert.Equal(2, displayWidth("🚀"))
+	assert.Equal(2, displayWidth("👍🏽"))
+	assert.Equal(2, displayWidth("👩‍💻"))
+	assert.Equal(4, displayWidth("🇩🇪🇯🇵"))
+
+	// Escape sequences take up no room at all.
+	assert.Equal(6, displayWidth("\x1b[1;31mdeploy\x1b[0m"))
+	assert.Equal(4, displayWidth("\x1b]8;;https://example.com\x07link\x1b]8;;\x1b\\"))
+}
+
+func TestStripANSI(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("plain", stripANSI("plain"))
+	assert.Equal("red", stripANSI("\x1b[31mred\x1b[0m"))
+	assert.Equal("cut", stripANSI("cut\x1b[3"))
+}
+
+func TestSplitWidth(t *testing.T) {
+	assert := assert.New(t)
+
+	head, tail := splitWidth("日本語", 4)
+	assert.Equal("日本", head)
+	assert.Equal("語", tail)
+
+	head, tail = splitWidth("日本語", 1)
+	assert.Equal("日", head)
+	assert.Equal("本語", tail)
+
+	// Escape sequences don't count, and stay with what's before them.
+	head, tail = splitWidth("\x1b[31mabc\x1b[0mdef", 3)
+	assert.Equal("\x1b[31mabc\x1b[0m", head)
+	assert.Equal("def", tail)
+
+	head, tail = splitWidth("abc", 5)
+	assert.Equal("abc", head)
+	assert.Equal("", tail)
+
+	// Joined emoji and flags are never split up, even if
+	// there's only room for part of one.
+	head, tail = splitWidth("ab👩‍💻cd", 3)
+	assert.Equal("ab", head)
+	assert.Equal("👩‍💻cd", tail)
+
+	head, tail = splitWidth("ab👩‍💻cd", 4)
+	assert.Equal("ab👩‍💻", head)
+	assert.Equal("cd", tail)
+
+	head, tail = splitWidth("🇩🇪🇯🇵", 2)
+	assert.Equal("🇩🇪", head)
+	assert.Equal("🇯🇵", tail)
+
+	head, tail = splitWidth("🇩🇪🇯🇵", 1)
+	assert.Equal("🇩🇪", head)
+	assert.Equal("🇯🇵", tail)
+}
+
+func TestSgrActive(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("", sgrActive("plain"))
+	assert.Equal("\x1b[31m", sgrActive("\x1b[31mred"))
+	assert.Equal("\x1b[1m\x1b[32m", sgrActive("\x1b[31mred\x1b[0m\x1b[1m\x1b[32mgreen"))
+	assert.Equal("", sgrActive("\x1b[31mred\x1b[m"))
+
+	// Only SGR sequences matter, not links or cursor movement.
+	assert.Equal("", sgrActive("\x1b]8;;https://example.com\x07link\x1b[2K"))
+}